@@ -0,0 +1,162 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+const (
+	// setEncodingVersion is the first byte of the binary encoding of a Set. It must
+	// be bumped whenever the encoding changes.
+	setEncodingVersion = 1
+)
+
+// MarshalBinary encodes the set in a compact, versioned binary format. The encoding
+// is a version byte, followed by the number of ranges and, for each range, the gap
+// from the end of the previous range and the range length, all as uvarints. So a
+// set of millions of addresses in a few blocks only takes a few bytes.
+func (s *Set) MarshalBinary() ([]byte, error) {
+	var (
+		buf  = make([]byte, 1+binary.MaxVarintLen64*(1+2*len(s.ranges)))
+		n    = 1
+		next uint64 // First address after the previous range
+	)
+
+	buf[0] = setEncodingVersion
+	n += binary.PutUvarint(buf[n:], uint64(len(s.ranges)))
+
+	for _, r := range s.ranges {
+		n += binary.PutUvarint(buf[n:], uint64(r.first)-next)
+		n += binary.PutUvarint(buf[n:], uint64(r.last-r.first))
+		next = uint64(r.last) + 1
+	}
+
+	return buf[:n], nil
+}
+
+// UnmarshalBinary decodes a set encoded by MarshalBinary, replacing the content of s.
+func (s *Set) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("ip/Set.UnmarshalBinary: Empty data")
+	}
+
+	if data[0] != setEncodingVersion {
+		return fmt.Errorf("ip/Set.UnmarshalBinary: Unsupported encoding version %d", data[0])
+	}
+
+	data = data[1:]
+
+	uvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, fmt.Errorf("ip/Set.UnmarshalBinary: Invalid data")
+		}
+
+		data = data[n:]
+
+		return v, nil
+	}
+
+	count, err := uvarint()
+	if err != nil {
+		return err
+	}
+
+	// Each range takes at least 2 bytes, don't trust the count beyond that
+	if count > uint64(len(data)/2) {
+		return fmt.Errorf("ip/Set.UnmarshalBinary: Invalid range count %d", count)
+	}
+
+	var (
+		rs   = make([]ipRange, 0, count)
+		next uint64
+	)
+
+	for i := uint64(0); i < count; i++ {
+		gap, err := uvarint()
+		if err != nil {
+			return err
+		}
+
+		length, err := uvarint()
+		if err != nil {
+			return err
+		}
+
+		if gap > 1<<32 || length > 1<<32 || next+gap+length > 0xffffffff {
+			return fmt.Errorf("ip/Set.UnmarshalBinary: Range out of IPv4 space")
+		}
+
+		first := next + gap
+		last := first + length
+
+		rs = append(rs, ipRange{first: uint32(first), last: uint32(last)})
+		next = last + 1
+	}
+
+	if len(data) != 0 {
+		return fmt.Errorf("ip/Set.UnmarshalBinary: %d trailing bytes", len(data))
+	}
+
+	s.ranges = mergeRanges(rs)
+
+	return nil
+}
+
+// MarshalText exports the set as the smallest list of CIDR blocks, one per line.
+func (s *Set) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, n := range s.CIDRs() {
+		buf.WriteString(n.String())
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalText reads a list of IP strings, one per line, replacing the content of
+// s. Each line can be a CIDR block, as written by MarshalText, or any IP string
+// accepted by Parse. Empty lines and lines starting with # are ignored.
+func (s *Set) UnmarshalText(text []byte) error {
+	var (
+		ns      = &Set{}
+		scanner = bufio.NewScanner(bytes.NewReader(text))
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if err := ns.Add(line); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	s.ranges = ns.ranges
+
+	return nil
+}
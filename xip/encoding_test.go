@@ -0,0 +1,79 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"encoding"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = &Set{}
+	_ encoding.BinaryUnmarshaler = &Set{}
+	_ encoding.TextMarshaler     = &Set{}
+	_ encoding.TextUnmarshaler   = &Set{}
+)
+
+func TestSetBinary(t *testing.T) {
+	s, err := NewSet("10", "192.168.1-100.1-200", "255.255.255.255", "0.0.0.0")
+	require.NoError(t, err)
+	require.True(t, s.Len() > 16000000)
+
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+	require.True(t, len(data) < 1024, "%d bytes", len(data))
+
+	var s2 Set
+	require.NoError(t, s2.UnmarshalBinary(data))
+	require.Equal(t, s.ranges, s2.ranges)
+
+	// Empty set
+	data, err = (&Set{}).MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, s2.UnmarshalBinary(data))
+	require.Equal(t, uint64(0), s2.Len())
+
+	// The full IPv4 space
+	all, err := NewSet("0.0.0.0/0")
+	require.NoError(t, err)
+	data, err = all.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, s2.UnmarshalBinary(data))
+	require.Equal(t, uint64(1)<<32, s2.Len())
+
+	require.Error(t, s2.UnmarshalBinary(nil))
+	require.Error(t, s2.UnmarshalBinary([]byte{2, 0}))
+	require.Error(t, s2.UnmarshalBinary([]byte{setEncodingVersion, 1, 0}))
+	require.Error(t, s2.UnmarshalBinary([]byte{setEncodingVersion, 0, 0}))
+	require.Error(t, s2.UnmarshalBinary([]byte{setEncodingVersion, 1, 0xff, 0xff, 0xff, 0xff, 0x0f, 1}))
+}
+
+func TestSetText(t *testing.T) {
+	s, err := NewSet("10.1.1.1-10", "192.168.1")
+	require.NoError(t, err)
+
+	text, err := s.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "10.1.1.1/32\n10.1.1.2/31\n10.1.1.4/30\n10.1.1.8/31\n10.1.1.10/32\n192.168.1.0/24\n", string(text))
+
+	var s2 Set
+	require.NoError(t, s2.UnmarshalText(append([]byte("# allowlist\n\n10.2.1,2.1\n"), text...)))
+	require.Equal(t, uint64(268), s2.Len())
+
+	require.Error(t, s2.UnmarshalText([]byte("10.1.1.1\n10.1.1.300\n")))
+	require.Equal(t, uint64(268), s2.Len())
+}
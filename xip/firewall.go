@@ -0,0 +1,303 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+)
+
+// The formatters below render the set collapsed to the smallest list of CIDR
+// blocks. Single addresses are written without the /32 suffix, except for the
+// cloud security group formats which require CIDR notation.
+
+// IPSet returns the set in the `ipset save` format, which can be loaded with
+// `ipset restore`. For example:
+//
+//   create allow hash:net family inet
+//   add allow 10.1.1.0/24
+//   add allow 10.1.2.1
+func (s *Set) IPSet(name string) string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "create %s hash:net family inet\n", name)
+
+	for _, n := range s.CIDRs() {
+		fmt.Fprintf(&buf, "add %s %s\n", name, hostOrCIDR(n))
+	}
+
+	return buf.String()
+}
+
+// Iptables returns one iptables rule per CIDR block, in the `iptables-save`
+// format, that appends to chain and jumps to target. For example:
+//
+//   -A INPUT -s 10.1.1.0/24 -j ACCEPT
+//   -A INPUT -s 10.1.2.1/32 -j ACCEPT
+func (s *Set) Iptables(chain, target string) string {
+	var buf bytes.Buffer
+
+	for _, n := range s.CIDRs() {
+		fmt.Fprintf(&buf, "-A %s -s %s -j %s\n", chain, n, target)
+	}
+
+	return buf.String()
+}
+
+// Nftables returns the set as an nftables anonymous set literal, which can be used
+// directly in a rule, such as `ip saddr { 10.1.1.0/24, 10.1.2.1 } accept`. nftables
+// has no empty anonymous sets, so an empty set is an error.
+func (s *Set) Nftables() (string, error) {
+	if len(s.ranges) == 0 {
+		return "", fmt.Errorf("ip/Nftables: Empty set")
+	}
+
+	return "{ " + strings.Join(hostOrCIDRs(s.CIDRs()), ", ") + " }", nil
+}
+
+// NftablesSet returns the set as a named nftables set declaration. For example:
+//
+//   set allow {
+//   	type ipv4_addr
+//   	flags interval
+//   	elements = { 10.1.1.0/24, 10.1.2.1 }
+//   }
+func (s *Set) NftablesSet(name string) string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "set %s {\n\ttype ipv4_addr\n\tflags interval\n", name)
+
+	if len(s.ranges) > 0 {
+		fmt.Fprintf(&buf, "\telements = { %s }\n", strings.Join(hostOrCIDRs(s.CIDRs()), ", "))
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+// PFTable returns the set as a pf table definition, such as
+// `table <allow> { 10.1.1.0/24, 10.1.2.1 }`, or `table <allow> { }` if it's empty.
+func (s *Set) PFTable(name string) string {
+	if len(s.ranges) == 0 {
+		return fmt.Sprintf("table <%s> { }", name)
+	}
+
+	return fmt.Sprintf("table <%s> { %s }", name, strings.Join(hostOrCIDRs(s.CIDRs()), ", "))
+}
+
+type awsIPRange struct {
+	CidrIp string `json:"CidrIp"`
+}
+
+type awsIPPermission struct {
+	IpProtocol string       `json:"IpProtocol"`
+	FromPort   *int         `json:"FromPort,omitempty"`
+	ToPort     *int         `json:"ToPort,omitempty"`
+	IpRanges   []awsIPRange `json:"IpRanges"`
+}
+
+// AWSIPPermission returns the set as the JSON of an AWS security group
+// IpPermission, as accepted by `aws ec2 authorize-security-group-ingress
+// --ip-permissions`. If the protocol is "-1" (all protocols), the ports are
+// omitted.
+func (s *Set) AWSIPPermission(protocol string, fromPort, toPort int) ([]byte, error) {
+	p := awsIPPermission{IpProtocol: protocol, IpRanges: []awsIPRange{}}
+
+	if protocol != "-1" {
+		p.FromPort, p.ToPort = &fromPort, &toPort
+	}
+
+	for _, n := range s.CIDRs() {
+		p.IpRanges = append(p.IpRanges, awsIPRange{CidrIp: n.String()})
+	}
+
+	return json.Marshal([]awsIPPermission{p})
+}
+
+type gcpAllowed struct {
+	IPProtocol string   `json:"IPProtocol"`
+	Ports      []string `json:"ports,omitempty"`
+}
+
+type gcpFirewall struct {
+	Name         string       `json:"name"`
+	Direction    string       `json:"direction"`
+	SourceRanges []string     `json:"sourceRanges"`
+	Allowed      []gcpAllowed `json:"allowed"`
+}
+
+// GCPFirewall returns the set as the JSON of a GCP ingress firewall rule that
+// allows the protocol and ports, such as "tcp" and "22" or "8000-8080", from the
+// addresses in the set.
+func (s *Set) GCPFirewall(name, protocol string, ports ...string) ([]byte, error) {
+	fw := gcpFirewall{
+		Name:         name,
+		Direction:    "INGRESS",
+		SourceRanges: []string{},
+		Allowed:      []gcpAllowed{{IPProtocol: protocol, Ports: ports}},
+	}
+
+	for _, n := range s.CIDRs() {
+		fw.SourceRanges = append(fw.SourceRanges, n.String())
+	}
+
+	return json.Marshal(fw)
+}
+
+// ParseIPSet reads the output of `ipset save` and returns the addresses of the set
+// with the given name. If name is empty, the addresses of all the sets are
+// returned. Entry options such as timeout are ignored.
+func ParseIPSet(r io.Reader, name string) (*Set, error) {
+	s := &Set{}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] != "add" || (name != "" && fields[1] != name) {
+			continue
+		}
+
+		if strings.IndexByte(fields[2], ':') != -1 {
+			// IPv6 entry
+			continue
+		}
+
+		if err := s.addEntry(fields[2]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// ParseNftables reads the output of `nft list ruleset` (or a file in the same
+// format) and returns the elements of the set with the given name. If name is
+// empty, the elements of all the ipv4_addr sets and maps are returned. Sets of
+// other types, IPv6 elements and element options such as timeout are ignored.
+func ParseNftables(r io.Reader, name string) (*Set, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		s     = &Set{}
+		text  = string(data)
+		cur   string // Name of the set we are in
+		ipv4  bool   // Is the current set of type ipv4_addr
+		depth int    // Brace depth at which the current set was declared
+		level int    // Current brace depth
+	)
+
+	for len(text) > 0 {
+		i := strings.IndexAny(text, "{}\n")
+		if i == -1 {
+			break
+		}
+
+		// The statements before the brace or newline, such as
+		// "type ipv4_addr; elements ="
+		stmts := strings.Split(text[:i], ";")
+		line := strings.TrimSpace(stmts[len(stmts)-1])
+		c := text[i]
+		text = text[i+1:]
+
+		if cur != "" {
+			for _, stmt := range stmts {
+				// type ipv4_addr, or type ipv4_addr : verdict for maps
+				if f := strings.Fields(stmt); len(f) >= 2 && f[0] == "type" {
+					ipv4 = f[1] == "ipv4_addr" && (len(f) == 2 || f[2] == ":")
+				}
+			}
+		}
+
+		switch c {
+		case '{':
+			level++
+
+			if f := strings.Fields(line); len(f) == 2 && (f[0] == "set" || f[0] == "map") {
+				cur, ipv4, depth = f[1], false, level
+				continue
+			}
+
+			if cur == "" || !strings.HasPrefix(line, "elements") {
+				continue
+			}
+
+			// elements = { ... }, which may span multiple lines
+			j := strings.IndexByte(text, '}')
+			if j == -1 {
+				return nil, fmt.Errorf("ip/ParseNftables: Unterminated elements in set %s", cur)
+			}
+
+			elems := text[:j]
+			text = text[j+1:]
+			level--
+
+			if !ipv4 || (name != "" && cur != name) {
+				continue
+			}
+
+			for _, e := range strings.Split(elems, ",") {
+				f := strings.Fields(e)
+				if len(f) == 0 || strings.IndexByte(f[0], ':') != -1 {
+					continue
+				}
+
+				if err := s.addEntry(f[0]); err != nil {
+					return nil, err
+				}
+			}
+
+		case '}':
+			if level == depth {
+				cur = ""
+			}
+
+			level--
+		}
+	}
+
+	return s, nil
+}
+
+func hostOrCIDR(n *net.IPNet) string {
+	if ones, _ := n.Mask.Size(); ones == 32 {
+		return n.IP.String()
+	}
+
+	return n.String()
+}
+
+func hostOrCIDRs(nets []*net.IPNet) []string {
+	res := make([]string, 0, len(nets))
+
+	for _, n := range nets {
+		res = append(res, hostOrCIDR(n))
+	}
+
+	return res
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirewallFormats(t *testing.T) {
+	s, err := NewSet("10.1.1.0-255", "10.1.2.1")
+	require.NoError(t, err)
+
+	require.Equal(t, "create allow hash:net family inet\nadd allow 10.1.1.0/24\nadd allow 10.1.2.1\n", s.IPSet("allow"))
+	require.Equal(t, "-A INPUT -s 10.1.1.0/24 -j ACCEPT\n-A INPUT -s 10.1.2.1/32 -j ACCEPT\n", s.Iptables("INPUT", "ACCEPT"))
+	require.Equal(t, "{ 10.1.1.0/24, 10.1.2.1 }", nftables(t, s))
+	require.Equal(t, "set allow {\n\ttype ipv4_addr\n\tflags interval\n\telements = { 10.1.1.0/24, 10.1.2.1 }\n}\n", s.NftablesSet("allow"))
+	require.Equal(t, "table <allow> { 10.1.1.0/24, 10.1.2.1 }", s.PFTable("allow"))
+
+	b, err := s.AWSIPPermission("tcp", 22, 22)
+	require.NoError(t, err)
+	require.Equal(t, `[{"IpProtocol":"tcp","FromPort":22,"ToPort":22,"IpRanges":[{"CidrIp":"10.1.1.0/24"},{"CidrIp":"10.1.2.1/32"}]}]`, string(b))
+
+	b, err = s.AWSIPPermission("-1", 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, `[{"IpProtocol":"-1","IpRanges":[{"CidrIp":"10.1.1.0/24"},{"CidrIp":"10.1.2.1/32"}]}]`, string(b))
+
+	b, err = s.GCPFirewall("allow-ssh", "tcp", "22")
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"allow-ssh","direction":"INGRESS","sourceRanges":["10.1.1.0/24","10.1.2.1/32"],"allowed":[{"IPProtocol":"tcp","ports":["22"]}]}`, string(b))
+}
+
+func TestParseIPSet(t *testing.T) {
+	save := `create allow hash:net family inet hashsize 1024 maxelem 65536
+add allow 10.1.1.0/25
+add allow 10.1.1.128/25 timeout 300
+add allow 10.1.2.1
+create deny hash:ip family inet
+add deny 192.168.1.1-192.168.1.3
+create allow6 hash:net family inet6
+add allow6 fe80::/64
+`
+
+	s, err := ParseIPSet(strings.NewReader(save), "allow")
+	require.NoError(t, err)
+	require.Equal(t, "{ 10.1.1.0/24, 10.1.2.1 }", nftables(t, s))
+
+	s, err = ParseIPSet(strings.NewReader(save), "")
+	require.NoError(t, err)
+	require.Equal(t, "{ 10.1.1.0/24, 10.1.2.1, 192.168.1.1, 192.168.1.2/31 }", nftables(t, s))
+
+	_, err = ParseIPSet(strings.NewReader("add allow 10.1.1.300\n"), "")
+	require.Error(t, err)
+}
+
+func TestParseNftables(t *testing.T) {
+	ruleset := `table inet filter {
+	set allow {
+		type ipv4_addr
+		flags interval
+		elements = { 10.1.1.0/25, 10.1.1.128/25,
+			     10.1.2.1 timeout 1h expires 59m, 10.1.3.1-10.1.3.2 }
+	}
+
+	set allow6 {
+		type ipv6_addr
+		elements = { fe80::1, fe80::2 }
+	}
+
+	set deny {
+		type ipv4_addr
+		elements = { 192.168.1.1 }
+	}
+
+	chain input {
+		type filter hook input priority 0; policy drop;
+		ip saddr @allow accept
+	}
+}
+`
+
+	s, err := ParseNftables(strings.NewReader(ruleset), "allow")
+	require.NoError(t, err)
+	require.Equal(t, "{ 10.1.1.0/24, 10.1.2.1, 10.1.3.1, 10.1.3.2 }", nftables(t, s))
+
+	s, err = ParseNftables(strings.NewReader(ruleset), "")
+	require.NoError(t, err)
+	require.Equal(t, "{ 10.1.1.0/24, 10.1.2.1, 10.1.3.1, 10.1.3.2, 192.168.1.1 }", nftables(t, s))
+
+	// Round trip
+	s2, err := ParseNftables(strings.NewReader(s.NftablesSet("allow")), "allow")
+	require.NoError(t, err)
+	require.Equal(t, s.CIDRs(), s2.CIDRs())
+
+	_, err = ParseNftables(strings.NewReader("set allow {\n elements = { 10.1.1.1"), "")
+	require.Error(t, err)
+
+	// Sets of other types are skipped, and the statements can be on one line
+	ruleset = `table inet filter {
+	set ports { type inet_service; elements = { 22, 80 } }
+	set allow { type ipv4_addr; flags interval; elements = { 10.1.1.0/24 } }
+	set pairs { type ipv4_addr . inet_service; elements = { 10.1.2.1 . 22 } }
+	map verdicts {
+		type ipv4_addr : verdict
+		elements = { 10.1.3.1 : accept }
+	}
+}
+`
+
+	s, err = ParseNftables(strings.NewReader(ruleset), "")
+	require.NoError(t, err)
+	require.Equal(t, "{ 10.1.1.0/24, 10.1.3.1 }", nftables(t, s))
+
+	s, err = ParseNftables(strings.NewReader(ruleset), "ports")
+	require.NoError(t, err)
+	_, err = s.Nftables()
+	require.Error(t, err)
+}
+
+func TestFirewallFormatsEmpty(t *testing.T) {
+	s := &Set{}
+
+	_, err := s.Nftables()
+	require.Error(t, err)
+	require.Equal(t, "set allow {\n\ttype ipv4_addr\n\tflags interval\n}\n", s.NftablesSet("allow"))
+	require.Equal(t, "table <allow> { }", s.PFTable("allow"))
+}
+
+func nftables(t *testing.T, s *Set) string {
+	res, err := s.Nftables()
+	require.NoError(t, err)
+	return res
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Duplicate is an entry that covers exactly the same addresses as an earlier entry.
+type Duplicate struct {
+	Entry int // Index of the duplicate entry
+	Of    int // Index of the first entry with the same addresses
+}
+
+// Covered is an entry whose addresses are all covered by other entries, so it can
+// be removed without changing the list.
+type Covered struct {
+	Entry int   // Index of the covered entry
+	By    []int // Indexes of the entries that together cover it
+}
+
+// Overlap is a pair of entries that share some, but not all, of their addresses.
+type Overlap struct {
+	A, B  int      // Indexes of the overlapping entries, A < B
+	Range []string // The shared addresses, as individual IPs or first-last ranges
+}
+
+// LintReport is the result of Lint. Entries are referred to by their index in the
+// list passed to Lint.
+type LintReport struct {
+	Entries      []string
+	Duplicates   []Duplicate
+	Covered      []Covered
+	Overlaps     []Overlap
+	Consolidated []*net.IPNet // The smallest list of CIDR blocks covering all entries
+}
+
+// Lint checks a list of IP strings, such as an allowlist, for redundancy. It reports
+// entries that duplicate earlier ones, entries that are fully covered by other
+// entries, and pairs of entries that partially overlap. It also returns the
+// smallest list of CIDR blocks that covers all the entries.
+//
+// A duplicate is only reported as such, and not again as covered or overlapping.
+// All the entries reported as covered can be removed together without changing the
+// addresses the list covers.
+func Lint(ips []string) (*LintReport, error) {
+	var (
+		rep     = &LintReport{Entries: ips}
+		entries = make([][]ipRange, len(ips))
+		dup     = make([]bool, len(ips))
+		all     []ipRange
+	)
+
+	for i, ip := range ips {
+		if strings.IndexByte(ip, ':') != -1 {
+			return nil, fmt.Errorf("ip/Lint: Invalid IP Address %s", ip)
+		}
+
+		rs, err := parseIPv4Ranges(ip)
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = rs
+		all = append(all, rs...)
+
+		for j := 0; j < i; j++ {
+			if !dup[j] && rangesEqual(entries[j], rs) {
+				dup[i] = true
+				rep.Duplicates = append(rep.Duplicates, Duplicate{Entry: i, Of: j})
+				break
+			}
+		}
+	}
+
+	rep.Consolidated = rangesToCIDRs(mergeRanges(all))
+
+	// Check the smallest entries first, and never count an entry that has already
+	// been reported as covered towards the coverage of another one, so all the
+	// covered entries can be removed together without losing any address.
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(x, y int) bool {
+		return rangesSize(entries[order[x]]) < rangesSize(entries[order[y]])
+	})
+
+	covered := make([]bool, len(entries))
+
+	for _, i := range order {
+		if dup[i] {
+			continue
+		}
+
+		var (
+			a      = entries[i]
+			others []ipRange
+			by     []int
+		)
+
+		for j, b := range entries {
+			if i == j || dup[j] || covered[j] || len(intersectRanges(a, b)) == 0 {
+				continue
+			}
+
+			others = append(others, b...)
+			by = append(by, j)
+		}
+
+		if len(by) > 0 && rangesContain(mergeRanges(others), a) {
+			covered[i] = true
+			rep.Covered = append(rep.Covered, Covered{Entry: i, By: by})
+		}
+	}
+
+	sort.Slice(rep.Covered, func(x, y int) bool { return rep.Covered[x].Entry < rep.Covered[y].Entry })
+
+	for i, a := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			if dup[i] || dup[j] {
+				continue
+			}
+
+			b := entries[j]
+
+			shared := intersectRanges(a, b)
+			if len(shared) == 0 || rangesContain(a, b) || rangesContain(b, a) {
+				continue
+			}
+
+			o := Overlap{A: i, B: j}
+			for _, r := range shared {
+				o.Range = append(o.Range, r.String())
+			}
+
+			rep.Overlaps = append(rep.Overlaps, o)
+		}
+	}
+
+	return rep, nil
+}
+
+// String returns the report in a human readable form, one finding per line.
+func (r *LintReport) String() string {
+	var buf bytes.Buffer
+
+	for _, d := range r.Duplicates {
+		fmt.Fprintf(&buf, "duplicate: %s is the same as %s\n", r.Entries[d.Entry], r.Entries[d.Of])
+	}
+
+	for _, c := range r.Covered {
+		var by []string
+		for _, i := range c.By {
+			by = append(by, r.Entries[i])
+		}
+
+		fmt.Fprintf(&buf, "covered: %s is covered by %s\n", r.Entries[c.Entry], strings.Join(by, ", "))
+	}
+
+	for _, o := range r.Overlaps {
+		fmt.Fprintf(&buf, "overlap: %s and %s share %s\n", r.Entries[o.A], r.Entries[o.B], strings.Join(o.Range, ", "))
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	rep, err := Lint([]string{
+		"10.1.1.0/24",    // 0
+		"10.1.1.1-10",    // 1: covered by 0
+		"10.1.1",         // 2: duplicate of 0
+		"10.1.2.0-127",   // 3: overlaps 5
+		"10.1.2.0-63",    // 4: covered by 3
+		"10.1.2.64-200",  // 5: covered by 3 and 6
+		"10.1.2.100-250", // 6
+		"10.1.2.201-255", // 7
+		"192.168.1.1",    // 8
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []Duplicate{{Entry: 2, Of: 0}}, rep.Duplicates)
+	require.Equal(t, []Covered{
+		{Entry: 1, By: []int{0}},
+		{Entry: 4, By: []int{3}},
+		{Entry: 5, By: []int{3, 6}},
+	}, rep.Covered)
+	require.Equal(t, []Overlap{
+		{A: 3, B: 5, Range: []string{"10.1.2.64-10.1.2.127"}},
+		{A: 3, B: 6, Range: []string{"10.1.2.100-10.1.2.127"}},
+		{A: 5, B: 6, Range: []string{"10.1.2.100-10.1.2.200"}},
+		{A: 6, B: 7, Range: []string{"10.1.2.201-10.1.2.250"}},
+	}, rep.Overlaps)
+	require.Equal(t, []string{
+		"10.1.1.0/24",
+		"10.1.2.0/24",
+		"192.168.1.1/32",
+	}, cidrStrings(rep.Consolidated))
+
+	require.Equal(t, "duplicate: 10.1.1 is the same as 10.1.1.0/24\n"+
+		"covered: 10.1.1.1-10 is covered by 10.1.1.0/24\n"+
+		"covered: 10.1.2.0-63 is covered by 10.1.2.0-127\n"+
+		"covered: 10.1.2.64-200 is covered by 10.1.2.0-127, 10.1.2.100-250\n"+
+		"overlap: 10.1.2.0-127 and 10.1.2.64-200 share 10.1.2.64-10.1.2.127\n"+
+		"overlap: 10.1.2.0-127 and 10.1.2.100-250 share 10.1.2.100-10.1.2.127\n"+
+		"overlap: 10.1.2.64-200 and 10.1.2.100-250 share 10.1.2.100-10.1.2.200\n"+
+		"overlap: 10.1.2.100-250 and 10.1.2.201-255 share 10.1.2.201-10.1.2.250\n", rep.String())
+}
+
+func TestLintMutualCover(t *testing.T) {
+	// Both halves are covered by the whole block, but the block is not reported as
+	// covered by the halves, since they are removed first.
+	rep, err := Lint([]string{"10.1.1.0/24", "10.1.1.0/25", "10.1.1.128/25"})
+	require.NoError(t, err)
+	require.Equal(t, []Covered{
+		{Entry: 1, By: []int{0}},
+		{Entry: 2, By: []int{0}},
+	}, rep.Covered)
+	require.Empty(t, rep.Overlaps)
+
+	_, err = Lint([]string{"10.1.1.0/24", "10.1.1.a"})
+	require.Error(t, err)
+}
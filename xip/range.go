@@ -0,0 +1,279 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"fmt"
+	"math/bits"
+	"net"
+	"sort"
+)
+
+// ipRange is an inclusive range of IPv4 addresses, stored as 32-bit integers so
+// the set operations don't need to expand every address.
+type ipRange struct {
+	first, last uint32
+}
+
+func (r ipRange) size() uint64 {
+	return uint64(r.last) - uint64(r.first) + 1
+}
+
+func (r ipRange) contains(o ipRange) bool {
+	return r.first <= o.first && o.last <= r.last
+}
+
+func (r ipRange) overlaps(o ipRange) bool {
+	return r.first <= o.last && o.first <= r.last
+}
+
+// cidrs returns the smallest list of CIDR blocks that covers exactly the range.
+func (r ipRange) cidrs() []*net.IPNet {
+	var nets []*net.IPNet
+
+	for cur := uint64(r.first); cur <= uint64(r.last); {
+		// The largest block that is aligned at cur...
+		n := 32
+		if cur != 0 {
+			n = bits.TrailingZeros32(uint32(cur))
+		}
+
+		// ...and does not go past the end of the range
+		for n > 0 && cur+(uint64(1)<<uint(n))-1 > uint64(r.last) {
+			n--
+		}
+
+		nets = append(nets, &net.IPNet{
+			IP:   uint32ToIP4(uint32(cur)),
+			Mask: net.CIDRMask(32-n, 32),
+		})
+
+		cur += uint64(1) << uint(n)
+	}
+
+	return nets
+}
+
+func (r ipRange) String() string {
+	if r.first == r.last {
+		return uint32ToIP4(r.first).String()
+	}
+
+	return uint32ToIP4(r.first).String() + "-" + uint32ToIP4(r.last).String()
+}
+
+// mergeRanges sorts the ranges and merges the ones that overlap or are adjacent.
+// The input slice is reused.
+func mergeRanges(rs []ipRange) []ipRange {
+	if len(rs) < 2 {
+		return rs
+	}
+
+	sort.Slice(rs, func(i, j int) bool { return rs[i].first < rs[j].first })
+
+	merged := rs[:1]
+
+	for _, r := range rs[1:] {
+		last := &merged[len(merged)-1]
+
+		if uint64(r.first) <= uint64(last.last)+1 {
+			if r.last > last.last {
+				last.last = r.last
+			}
+		} else {
+			merged = append(merged, r)
+		}
+	}
+
+	return merged
+}
+
+// rangesToCIDRs converts a list of merged ranges into CIDR blocks.
+func rangesToCIDRs(rs []ipRange) []*net.IPNet {
+	var nets []*net.IPNet
+
+	for _, r := range rs {
+		nets = append(nets, r.cidrs()...)
+	}
+
+	return nets
+}
+
+// netToRange returns the range of addresses covered by an IPv4 CIDR block.
+func netToRange(n *net.IPNet) (ipRange, error) {
+	ip := n.IP.To4()
+	ones, bits := n.Mask.Size()
+
+	if ip == nil || bits != 32 {
+		return ipRange{}, fmt.Errorf("ip/netToRange: %s is not an IPv4 CIDR block", n)
+	}
+
+	first := ip4ToUint32(ip) & ip4ToUint32(net.IP(n.Mask))
+
+	return ipRange{first: first, last: first | ^uint32(0)>>uint(ones)}, nil
+}
+
+// parseIPv4Ranges parses the IP string, as accepted by ParseIPv4, into a list of
+// merged ranges without expanding the individual addresses.
+func parseIPv4Ranges(ip string) ([]ipRange, error) {
+	addr, mask, err := splitIPv4CIDR(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := parseIPv4Octets(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m     = ip4ToUint32(net.IP(mask))
+		runs  = octetRuns(octets[3])
+		rs    []ipRange
+		first = uniqueOctets(octets[0])
+		sec   = uniqueOctets(octets[1])
+		third = uniqueOctets(octets[2])
+	)
+
+	for _, o1 := range first {
+		for _, o2 := range sec {
+			for _, o3 := range third {
+				base := uint32(o1)<<24 | uint32(o2)<<16 | uint32(o3)<<8
+
+				for _, r := range runs {
+					rs = append(rs, ipRange{
+						first: (base | r.first) & m,
+						last:  (base | r.last) | ^m,
+					})
+				}
+			}
+		}
+	}
+
+	return mergeRanges(rs), nil
+}
+
+// uniqueOctets returns the sorted, de-duplicated octet values.
+func uniqueOctets(o []byte) []byte {
+	var seen [256]bool
+	var res []byte
+
+	for _, b := range o {
+		seen[b] = true
+	}
+
+	for i, ok := range seen {
+		if ok {
+			res = append(res, byte(i))
+		}
+	}
+
+	return res
+}
+
+// octetRuns groups the octet values into runs of consecutive values.
+func octetRuns(o []byte) []ipRange {
+	var rs []ipRange
+
+	for _, b := range uniqueOctets(o) {
+		if n := len(rs); n > 0 && rs[n-1].last+1 == uint32(b) {
+			rs[n-1].last = uint32(b)
+		} else {
+			rs = append(rs, ipRange{first: uint32(b), last: uint32(b)})
+		}
+	}
+
+	return rs
+}
+
+func ip4ToUint32(ip net.IP) uint32 {
+	ip = ip.To4()
+	return uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+}
+
+func uint32ToIP4(n uint32) net.IP {
+	return net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// rangesContain returns true if every address in b is also in a. Both lists must
+// be merged.
+func rangesContain(a, b []ipRange) bool {
+	i := 0
+
+	for _, r := range b {
+		for i < len(a) && a[i].last < r.first {
+			i++
+		}
+
+		if i == len(a) || !a[i].contains(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// intersectRanges returns the addresses that are in both a and b. Both lists must
+// be merged.
+func intersectRanges(a, b []ipRange) []ipRange {
+	var rs []ipRange
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if a[i].overlaps(b[j]) {
+			r := a[i]
+			if b[j].first > r.first {
+				r.first = b[j].first
+			}
+
+			if b[j].last < r.last {
+				r.last = b[j].last
+			}
+
+			rs = append(rs, r)
+		}
+
+		if a[i].last < b[j].last {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return rs
+}
+
+func rangesEqual(a, b []ipRange) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func rangesSize(rs []ipRange) uint64 {
+	var n uint64
+
+	for _, r := range rs {
+		n += r.size()
+	}
+
+	return n
+}
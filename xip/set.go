@@ -0,0 +1,152 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Set is a set of IPv4 addresses. Internally the addresses are kept as a sorted
+// list of merged ranges, so large sets such as 10.0.0.0/8 take very little memory.
+// The zero value is an empty set.
+type Set struct {
+	ranges []ipRange
+}
+
+// NewSet returns a set with all the addresses represented by the IP strings. See
+// Parse for the format of the IP strings.
+func NewSet(ips ...string) (*Set, error) {
+	s := &Set{}
+
+	for _, ip := range ips {
+		if err := s.Add(ip); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Add adds all the addresses represented by the IP string to the set.
+func (s *Set) Add(ip string) error {
+	if strings.IndexByte(ip, ':') != -1 {
+		return fmt.Errorf("ip/Set.Add: Invalid IP Address %s", ip)
+	}
+
+	rs, err := parseIPv4Ranges(ip)
+	if err != nil {
+		return err
+	}
+
+	s.ranges = mergeRanges(append(s.ranges, rs...))
+
+	return nil
+}
+
+// AddCIDR adds all the addresses in the CIDR block to the set.
+func (s *Set) AddCIDR(n *net.IPNet) error {
+	r, err := netToRange(n)
+	if err != nil {
+		return err
+	}
+
+	s.ranges = mergeRanges(append(s.ranges, r))
+
+	return nil
+}
+
+// AddRange adds all the addresses from first to last, inclusive, to the set.
+func (s *Set) AddRange(first, last net.IP) error {
+	if first.To4() == nil || last.To4() == nil {
+		return fmt.Errorf("ip/Set.AddRange: Invalid IPv4 range %s-%s", first, last)
+	}
+
+	r := ipRange{first: ip4ToUint32(first), last: ip4ToUint32(last)}
+	if r.first > r.last {
+		return fmt.Errorf("ip/Set.AddRange: Invalid IPv4 range %s-%s", first, last)
+	}
+
+	s.ranges = mergeRanges(append(s.ranges, r))
+
+	return nil
+}
+
+// Contains returns true if the IP address is in the set.
+func (s *Set) Contains(ip net.IP) bool {
+	if ip.To4() == nil {
+		return false
+	}
+
+	n := ip4ToUint32(ip)
+	i := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i].last >= n })
+
+	return i < len(s.ranges) && s.ranges[i].first <= n
+}
+
+// Len returns the number of addresses in the set.
+func (s *Set) Len() uint64 {
+	return rangesSize(s.ranges)
+}
+
+// CIDRs returns the smallest list of CIDR blocks that covers exactly the addresses
+// in the set, in ascending order.
+func (s *Set) CIDRs() []*net.IPNet {
+	return rangesToCIDRs(s.ranges)
+}
+
+// IPs expands the set into the list of individual IPs, in ascending order.
+func (s *Set) IPs() []net.IP {
+	var ips []net.IP
+
+	for _, r := range s.ranges {
+		for n := uint64(r.first); n <= uint64(r.last); n++ {
+			ips = append(ips, uint32ToIP4(uint32(n)))
+		}
+	}
+
+	return ips
+}
+
+// addEntry adds an address, CIDR block, or full address range such as
+// 10.1.1.1-10.1.1.20, as found in firewall configurations, to the set.
+func (s *Set) addEntry(e string) error {
+	if i := strings.IndexByte(e, '-'); i != -1 {
+		first, last := net.ParseIP(e[:i]), net.ParseIP(e[i+1:])
+		if first == nil || last == nil {
+			return fmt.Errorf("ip/Set.addEntry: Invalid IP range %s", e)
+		}
+
+		return s.AddRange(first, last)
+	}
+
+	if strings.IndexByte(e, '/') != -1 {
+		_, n, err := net.ParseCIDR(e)
+		if err != nil {
+			return err
+		}
+
+		return s.AddCIDR(n)
+	}
+
+	ip := net.ParseIP(e)
+	if ip == nil {
+		return fmt.Errorf("ip/Set.addEntry: Invalid IP Address %s", e)
+	}
+
+	return s.AddRange(ip, ip)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet(t *testing.T) {
+	s, err := NewSet("10.1.1.0/25", "10.1.1.128-255", "10.1.2.1,2")
+	require.NoError(t, err)
+	require.Equal(t, uint64(258), s.Len())
+	require.Equal(t, []string{"10.1.1.0/24", "10.1.2.1/32", "10.1.2.2/32"}, cidrStrings(s.CIDRs()))
+
+	require.True(t, s.Contains(net.ParseIP("10.1.1.77")))
+	require.True(t, s.Contains(net.ParseIP("10.1.2.2")))
+	require.False(t, s.Contains(net.ParseIP("10.1.2.3")))
+	require.False(t, s.Contains(net.ParseIP("::1")))
+
+	require.NoError(t, s.AddCIDR(mustCIDR(t, "10.1.2.0/30")))
+	require.Equal(t, []string{"10.1.1.0/24", "10.1.2.0/30"}, cidrStrings(s.CIDRs()))
+
+	require.NoError(t, s.AddRange(net.ParseIP("10.1.2.4"), net.ParseIP("10.1.2.5")))
+	require.Len(t, s.IPs(), 262)
+
+	require.Error(t, s.AddRange(net.ParseIP("10.1.2.5"), net.ParseIP("10.1.2.4")))
+	require.Error(t, s.Add("10.1.1.256"))
+
+	_, err = NewSet("10.1.1.1", "fe80::1")
+	require.Error(t, err)
+
+	var empty Set
+	require.Equal(t, uint64(0), empty.Len())
+	require.False(t, empty.Contains(net.ParseIP("10.1.1.1")))
+}
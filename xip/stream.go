@@ -0,0 +1,176 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Stream parses the IP string, see Parse, and sends the IPs on the returned channel
+// as they are generated, so the consumers can start before the whole list is
+// expanded. The IPs are sent in ascending order, without duplicates.
+//
+// The channel is closed once all the IPs are sent, or as soon as ctx is done; check
+// ctx.Err() to tell the two apart. Errors in the IP string are returned right away,
+// before anything is sent.
+func Stream(ctx context.Context, ip string) (<-chan net.IP, error) {
+	g, err := newIPv4Generator("Stream", ip)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan net.IP)
+
+	go func() {
+		defer close(ch)
+
+		g.generate(func(n uint32) bool {
+			select {
+			case ch <- uint32ToIP4(n):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return ch, nil
+}
+
+// StreamBatch is the same as Stream, but sends the IPs in batches of up to size IPs,
+// which is cheaper for large expansions. Only the last batch can be smaller than
+// size.
+func StreamBatch(ctx context.Context, ip string, size int) (<-chan []net.IP, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("ip/StreamBatch: Invalid batch size %d", size)
+	}
+
+	g, err := newIPv4Generator("StreamBatch", ip)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan []net.IP)
+
+	go func() {
+		defer close(ch)
+
+		send := func(batch []net.IP) bool {
+			select {
+			case ch <- batch:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		batch := make([]net.IP, 0, size)
+
+		ok := g.generate(func(n uint32) bool {
+			batch = append(batch, uint32ToIP4(n))
+			if len(batch) < size {
+				// Still check for cancellation while filling up the batch
+				return ctx.Err() == nil
+			}
+
+			if !send(batch) {
+				return false
+			}
+
+			batch = make([]net.IP, 0, size)
+
+			return true
+		})
+
+		if ok && len(batch) > 0 {
+			send(batch)
+		}
+	}()
+
+	return ch, nil
+}
+
+// ipv4Generator walks the octet cross product of a parsed IP string in ascending
+// order, without building the list of IPs.
+type ipv4Generator struct {
+	octets [3][]byte
+	runs   []ipRange
+	mask   uint32
+}
+
+// newIPv4Generator returns the generator of the IP string, fn is the name of the
+// calling function for the errors.
+func newIPv4Generator(fn, ip string) (*ipv4Generator, error) {
+	if strings.IndexByte(ip, ':') != -1 {
+		return nil, fmt.Errorf("ip/%s: Invalid IP Address %s", fn, ip)
+	}
+
+	addr, mask, err := splitIPv4CIDR(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := parseIPv4Octets(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ipv4Generator{
+		octets: [3][]byte{uniqueOctets(octets[0]), uniqueOctets(octets[1]), uniqueOctets(octets[2])},
+		runs:   octetRuns(octets[3]),
+		mask:   ip4ToUint32(net.IP(mask)),
+	}, nil
+}
+
+// generate calls emit for each IP, and stops early if emit returns false. It returns
+// false if it was stopped early.
+func (g *ipv4Generator) generate(emit func(uint32) bool) bool {
+	// Since the cross product is generated in ascending order, and the CIDR blocks
+	// of ascending addresses are also ascending, anything below next has already
+	// been emitted by an earlier block.
+	var next uint64
+
+	for _, o1 := range g.octets[0] {
+		for _, o2 := range g.octets[1] {
+			for _, o3 := range g.octets[2] {
+				base := uint32(o1)<<24 | uint32(o2)<<16 | uint32(o3)<<8
+
+				for _, r := range g.runs {
+					first := uint64((base | r.first) & g.mask)
+					last := uint64((base | r.last) | ^g.mask)
+
+					if first < next {
+						first = next
+					}
+
+					for n := first; n <= last; n++ {
+						if !emit(uint32(n)) {
+							return false
+						}
+					}
+
+					if last+1 > next {
+						next = last + 1
+					}
+				}
+			}
+		}
+	}
+
+	return true
+}
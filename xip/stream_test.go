@@ -0,0 +1,108 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	for i, ip := range ips {
+		ch, err := Stream(context.Background(), ip)
+		require.NoError(t, err)
+
+		m := make(map[[4]byte]bool)
+		var prev uint32
+
+		for ip2 := range ch {
+			var tmp [4]byte
+			copy(tmp[:], ip2.To4())
+			require.False(t, m[tmp], "duplicate %s", ip2)
+			m[tmp] = true
+
+			// Ascending order
+			n := ip4ToUint32(ip2)
+			require.True(t, len(m) == 1 || n > prev)
+			prev = n
+		}
+
+		require.Equal(t, results[i], m, ip)
+	}
+
+	_, err := Stream(context.Background(), "10.1.1.256")
+	require.Error(t, err)
+}
+
+func TestStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := Stream(ctx, "10")
+	require.NoError(t, err)
+
+	n := 0
+	for range ch {
+		n++
+		if n == 10 {
+			cancel()
+		}
+	}
+
+	require.True(t, n < 100)
+	require.Equal(t, context.Canceled, ctx.Err())
+}
+
+func TestStreamBatch(t *testing.T) {
+	ch, err := StreamBatch(context.Background(), "10.1.1.0/28", 6)
+	require.NoError(t, err)
+
+	var sizes []int
+	var all []net.IP
+
+	for batch := range ch {
+		sizes = append(sizes, len(batch))
+		all = append(all, batch...)
+	}
+
+	require.Equal(t, []int{6, 6, 4}, sizes)
+	require.Equal(t, "10.1.1.0", all[0].String())
+	require.Equal(t, "10.1.1.15", all[15].String())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err = StreamBatch(ctx, "10", 1000)
+	require.NoError(t, err)
+
+	<-ch
+	cancel()
+
+	n := 0
+	for range ch {
+		n++
+	}
+
+	require.True(t, n <= 1)
+
+	_, err = StreamBatch(context.Background(), "10.1.1.1", 0)
+	require.Error(t, err)
+	_, err = StreamBatch(context.Background(), "fe80::1", 10)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "ip/StreamBatch:")
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"fmt"
+	"math/bits"
+	"net"
+	"strings"
+)
+
+// ParseCIDRs takes the same IP strings as Parse, but instead of expanding them to
+// individual IPs, it returns the smallest list of CIDR blocks that covers exactly
+// the same addresses, in ascending order.
+//
+// For example:
+//   10.1.1.1      -> 10.1.1.1/32
+//   10.1.1.1-10   -> 10.1.1.1/32, 10.1.1.2/31, 10.1.1.4/30, 10.1.1.8/31, 10.1.1.10/32
+//   10.1-2        -> 10.1.0.0/16, 10.2.0.0/16
+//   10.1.1,2.1/24 -> 10.1.1.0/24, 10.1.2.0/24
+func ParseCIDRs(ip string) ([]*net.IPNet, error) {
+	if strings.IndexByte(ip, ':') != -1 {
+		return nil, fmt.Errorf("ip/ParseCIDRs: Invalid IP Address %s", ip)
+	}
+
+	rs, err := parseIPv4Ranges(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	return rangesToCIDRs(rs), nil
+}
+
+// Subnets splits the prefix into all of its subnets with the new prefix length.
+// For example, splitting 10.0.0.0/16 with newLen 24 returns the 256 blocks from
+// 10.0.0.0/24 to 10.0.255.0/24.
+func Subnets(prefix *net.IPNet, newLen int) ([]*net.IPNet, error) {
+	r, err := netToRange(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	ones, _ := prefix.Mask.Size()
+	if newLen < ones || newLen > 32 {
+		return nil, fmt.Errorf("ip/Subnets: Invalid prefix length %d for %s", newLen, prefix)
+	}
+
+	var (
+		nets []*net.IPNet
+		step = uint64(1) << uint(32-newLen)
+		mask = net.CIDRMask(newLen, 32)
+	)
+
+	for cur := uint64(r.first); cur <= uint64(r.last); cur += step {
+		nets = append(nets, &net.IPNet{IP: uint32ToIP4(uint32(cur)), Mask: mask})
+	}
+
+	return nets, nil
+}
+
+// Supernet returns the smallest CIDR block that contains all of the given prefixes.
+// For example, the supernet of 10.1.2.0/24 and 10.1.5.0/24 is 10.1.0.0/21.
+func Supernet(prefixes ...*net.IPNet) (*net.IPNet, error) {
+	if len(prefixes) == 0 {
+		return nil, fmt.Errorf("ip/Supernet: No prefixes given")
+	}
+
+	var lo, hi uint32 = ^uint32(0), 0
+
+	for _, p := range prefixes {
+		r, err := netToRange(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.first < lo {
+			lo = r.first
+		}
+
+		if r.last > hi {
+			hi = r.last
+		}
+	}
+
+	// The common prefix of the lowest and highest address
+	ones := bits.LeadingZeros32(lo ^ hi)
+	mask := net.CIDRMask(ones, 32)
+
+	return &net.IPNet{IP: uint32ToIP4(lo).Mask(mask), Mask: mask}, nil
+}
+
+// NextFree returns the lowest block with the prefix length size inside the pool
+// that does not overlap with any of the allocated blocks. Allocated blocks outside
+// of the pool are ignored. An error is returned if the pool is full.
+func NextFree(pool *net.IPNet, allocated []*net.IPNet, size int) (*net.IPNet, error) {
+	pr, err := netToRange(pool)
+	if err != nil {
+		return nil, err
+	}
+
+	ones, _ := pool.Mask.Size()
+	if size < ones || size > 32 {
+		return nil, fmt.Errorf("ip/NextFree: Invalid prefix length %d for %s", size, pool)
+	}
+
+	used, err := usedRanges(pr, allocated)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		block = uint64(1) << uint(32-size)
+		cur   = uint64(pr.first)
+	)
+
+	for _, u := range used {
+		if uint64(u.last) < cur {
+			continue
+		}
+
+		if cur+block-1 < uint64(u.first) {
+			break
+		}
+
+		// Move to the first aligned block after the allocated range
+		cur = (uint64(u.last) + block) &^ (block - 1)
+	}
+
+	if cur+block-1 > uint64(pr.last) {
+		return nil, fmt.Errorf("ip/NextFree: No free /%d block left in %s", size, pool)
+	}
+
+	return &net.IPNet{IP: uint32ToIP4(uint32(cur)), Mask: net.CIDRMask(size, 32)}, nil
+}
+
+// Usage is the address utilization of a pool as reported by Utilization.
+type Usage struct {
+	Total uint64 // Number of addresses in the pool
+	Used  uint64 // Number of addresses covered by at least one allocated block
+	Free  uint64 // Number of addresses not allocated
+}
+
+// Percent returns the percentage of the pool that is used.
+func (u Usage) Percent() float64 {
+	if u.Total == 0 {
+		return 0
+	}
+
+	return float64(u.Used) * 100 / float64(u.Total)
+}
+
+// Utilization reports how many addresses of the pool are covered by the allocated
+// blocks. Overlapping allocations are only counted once, and the parts of the
+// allocations outside of the pool are ignored.
+func Utilization(pool *net.IPNet, allocated []*net.IPNet) (Usage, error) {
+	pr, err := netToRange(pool)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	used, err := usedRanges(pr, allocated)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	u := Usage{Total: pr.size()}
+
+	for _, r := range used {
+		u.Used += r.size()
+	}
+
+	u.Free = u.Total - u.Used
+
+	return u, nil
+}
+
+// usedRanges returns the merged ranges of the allocated blocks, clipped to the pool.
+func usedRanges(pool ipRange, allocated []*net.IPNet) ([]ipRange, error) {
+	var rs []ipRange
+
+	for _, a := range allocated {
+		r, err := netToRange(a)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pool.overlaps(r) {
+			continue
+		}
+
+		if r.first < pool.first {
+			r.first = pool.first
+		}
+
+		if r.last > pool.last {
+			r.last = pool.last
+		}
+
+		rs = append(rs, r)
+	}
+
+	return mergeRanges(rs), nil
+}
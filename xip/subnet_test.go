@@ -0,0 +1,180 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netx
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustCIDR(t *testing.T, s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	require.NoError(t, err)
+	return n
+}
+
+func cidrStrings(nets []*net.IPNet) []string {
+	var res []string
+	for _, n := range nets {
+		res = append(res, n.String())
+	}
+	return res
+}
+
+func TestParseCIDRs(t *testing.T) {
+	tests := map[string][]string{
+		"10.1.1.1":      {"10.1.1.1/32"},
+		"10.1.1.1-10":   {"10.1.1.1/32", "10.1.1.2/31", "10.1.1.4/30", "10.1.1.8/31", "10.1.1.10/32"},
+		"10.1-2":        {"10.1.0.0/16", "10.2.0.0/16"},
+		"10.1-3":        {"10.1.0.0/16", "10.2.0.0/15"},
+		"10.1.1,2.1/24": {"10.1.1.0/24", "10.1.2.0/24"},
+		"10.1.1/28":     {"10.1.1.0/24"},
+		"10.1.1.128/25": {"10.1.1.128/25"},
+		"10":            {"10.0.0.0/8"},
+		"0.0.0.0/0":     {"0.0.0.0/0"},
+	}
+
+	for ip, expected := range tests {
+		nets, err := ParseCIDRs(ip)
+		require.NoError(t, err, ip)
+		require.Equal(t, expected, cidrStrings(nets), ip)
+	}
+
+	// Make sure the CIDRs cover exactly the same addresses as Parse
+	for i, ip := range ips {
+		nets, err := ParseCIDRs(ip)
+		require.NoError(t, err)
+
+		m := make(map[[4]byte]bool)
+		for _, n := range nets {
+			r, err := netToRange(n)
+			require.NoError(t, err)
+
+			for a := uint64(r.first); a <= uint64(r.last); a++ {
+				var tmp [4]byte
+				copy(tmp[:], uint32ToIP4(uint32(a)).To4())
+				m[tmp] = true
+			}
+		}
+
+		require.Equal(t, results[i], m, ip)
+	}
+
+	_, err := ParseCIDRs("10.1.1.1/33")
+	require.Error(t, err)
+
+	_, err = ParseCIDRs("::1")
+	require.Error(t, err)
+}
+
+func TestSubnets(t *testing.T) {
+	nets, err := Subnets(mustCIDR(t, "10.0.0.0/16"), 24)
+	require.NoError(t, err)
+	require.Len(t, nets, 256)
+	require.Equal(t, "10.0.0.0/24", nets[0].String())
+	require.Equal(t, "10.0.255.0/24", nets[255].String())
+
+	nets, err = Subnets(mustCIDR(t, "10.0.0.0/24"), 24)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.0/24"}, cidrStrings(nets))
+
+	_, err = Subnets(mustCIDR(t, "10.0.0.0/24"), 16)
+	require.Error(t, err)
+
+	_, err = Subnets(mustCIDR(t, "10.0.0.0/24"), 33)
+	require.Error(t, err)
+}
+
+func TestSupernet(t *testing.T) {
+	n, err := Supernet(mustCIDR(t, "10.1.2.0/24"), mustCIDR(t, "10.1.5.0/24"))
+	require.NoError(t, err)
+	require.Equal(t, "10.1.0.0/21", n.String())
+
+	n, err = Supernet(mustCIDR(t, "10.1.2.0/24"))
+	require.NoError(t, err)
+	require.Equal(t, "10.1.2.0/24", n.String())
+
+	n, err = Supernet(mustCIDR(t, "10.0.0.0/8"), mustCIDR(t, "192.168.0.0/16"))
+	require.NoError(t, err)
+	require.Equal(t, "0.0.0.0/0", n.String())
+
+	_, err = Supernet()
+	require.Error(t, err)
+}
+
+func TestNextFree(t *testing.T) {
+	pool := mustCIDR(t, "10.0.0.0/24")
+
+	n, err := NextFree(pool, nil, 26)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.0/26", n.String())
+
+	allocated := []*net.IPNet{
+		mustCIDR(t, "10.0.0.0/26"),
+		mustCIDR(t, "10.0.0.70/31"),
+		mustCIDR(t, "192.168.0.0/16"),
+	}
+
+	n, err = NextFree(pool, allocated, 26)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.128/26", n.String())
+
+	n, err = NextFree(pool, allocated, 28)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.80/28", n.String())
+
+	n, err = NextFree(pool, allocated, 30)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.64/30", n.String())
+
+	_, err = NextFree(pool, []*net.IPNet{mustCIDR(t, "10.0.0.0/25"), mustCIDR(t, "10.0.0.192/26")}, 25)
+	require.Error(t, err)
+
+	n, err = NextFree(mustCIDR(t, "255.255.255.0/24"), []*net.IPNet{mustCIDR(t, "255.255.255.0/25")}, 25)
+	require.NoError(t, err)
+	require.Equal(t, "255.255.255.128/25", n.String())
+
+	_, err = NextFree(mustCIDR(t, "255.255.255.0/24"), []*net.IPNet{mustCIDR(t, "255.255.255.128/25")}, 24)
+	require.Error(t, err)
+}
+
+func TestUtilization(t *testing.T) {
+	u, err := Utilization(mustCIDR(t, "10.0.0.0/24"), []*net.IPNet{
+		mustCIDR(t, "10.0.0.0/26"),
+		mustCIDR(t, "10.0.0.0/27"),
+		mustCIDR(t, "10.0.0.128/25"),
+		mustCIDR(t, "10.0.0.0/8"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, Usage{Total: 256, Used: 256, Free: 0}, u)
+
+	u, err = Utilization(mustCIDR(t, "10.0.0.0/24"), []*net.IPNet{
+		mustCIDR(t, "10.0.0.0/26"),
+		mustCIDR(t, "10.0.0.0/27"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, Usage{Total: 256, Used: 64, Free: 192}, u)
+	require.Equal(t, 25.0, u.Percent())
+}
+
+func TestParseIPv4OctetsError(t *testing.T) {
+	for _, ip := range []string{"10.1.300.1", "10.1.1.1.1", "10.1.x.1"} {
+		octets, err := parseIPv4Octets(ip)
+		require.Error(t, err, ip)
+		require.Equal(t, [4][]byte{}, octets, ip)
+	}
+}
@@ -68,36 +68,70 @@ func Parse(ip string) ([]net.IP, error) {
 
 // ParseIPv4 is called by ParseIP for IPv4 addresses. See ParseIP for more detials.
 func ParseIPv4(ip string) ([]net.IP, error) {
-	parts := strings.Split(ip, "/")
-	if len(parts) > 2 {
-		return nil, fmt.Errorf("parse/ParseIPv4: Invalid IP Address %s", ip)
+	addr, mask, err := splitIPv4CIDR(ip)
+	if err != nil {
+		return nil, err
 	}
 
-	ips, err := parseIPv4(parts[0])
+	ips, err := parseIPv4(addr)
 	if err != nil {
 		return nil, err
 	}
 
-	var cidr int64
+	return parseIPv4CIDR(ips, mask)
+}
 
-	if len(parts) != 2 {
-		cidr = 32
-	} else {
+// splitIPv4CIDR splits the IP string into the address part and the CIDR mask. If
+// there's no CIDR suffix, the mask is /32.
+func splitIPv4CIDR(ip string) (string, net.IPMask, error) {
+	parts := strings.Split(ip, "/")
+	if len(parts) > 2 {
+		return "", nil, fmt.Errorf("parse/ParseIPv4: Invalid IP Address %s", ip)
+	}
+
+	var cidr int64 = 32
+
+	if len(parts) == 2 {
+		var err error
 
 		cidr, err = strconv.ParseInt(parts[1], 0, 8)
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
 
-		if cidr > 32 {
-			return nil, fmt.Errorf("parse/ParseIPv4: Invalid IP Address %s: Invalid CIDR notation", ip)
+		if cidr < 0 || cidr > 32 {
+			return "", nil, fmt.Errorf("parse/ParseIPv4: Invalid IP Address %s: Invalid CIDR notation", ip)
 		}
 	}
 
-	return parseIPv4CIDR(ips, net.CIDRMask(int(cidr), 32))
+	return parts[0], net.CIDRMask(int(cidr), 32), nil
 }
 
 func parseIPv4(ip string) ([]net.IP, error) {
+	octets, err := parseIPv4Octets(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+
+	// Create a list of IPs
+	for _, o1 := range octets[0] {
+		for _, o2 := range octets[1] {
+			for _, o3 := range octets[2] {
+				for _, o4 := range octets[3] {
+					ips = append(ips, net.IPv4(o1, o2, o3, o4))
+				}
+			}
+		}
+	}
+
+	return ips, nil
+}
+
+// parseIPv4Octets parses the IP string, without the CIDR suffix, into the list of
+// values each of the 4 octets can take.
+func parseIPv4Octets(ip string) ([4][]byte, error) {
 	var (
 		octets [4][]byte    // Octet 1, 2, 3, 4 of the IP address
 		state  = stateOctet // Current state of the parser
@@ -122,7 +156,7 @@ func parseIPv4(ip string) ([]net.IP, error) {
 			case stateOctet:
 				if oi >= 3 && b != ',' {
 					// Should never see dot when we are in octet 4
-					return nil, fmt.Errorf("ip/parseIPv4(1): Invalid IP address %s", ip)
+					return [4][]byte{}, fmt.Errorf("ip/parseIPv4(1): Invalid IP address %s", ip)
 				}
 
 				octets[oi] = append(octets[oi], byte(value))
@@ -133,7 +167,7 @@ func parseIPv4(ip string) ([]net.IP, error) {
 				}
 
 			default:
-				return nil, fmt.Errorf("ip/parseIPv4(2): Invalid IP address %s", ip)
+				return [4][]byte{}, fmt.Errorf("ip/parseIPv4(2): Invalid IP address %s", ip)
 			}
 
 			if b == '/' {
@@ -161,13 +195,13 @@ func parseIPv4(ip string) ([]net.IP, error) {
 		case b >= '0' && b <= '9':
 			value = value*10 + int(b-'0')
 			if value > maxOctetValue {
-				return nil, fmt.Errorf("ip/parseIPv4(4): Invalid IP address %s: octet value larger than than %x", ip, maxOctetValue)
+				return [4][]byte{}, fmt.Errorf("ip/parseIPv4(4): Invalid IP address %s: octet value larger than than %x", ip, maxOctetValue)
 			}
 
 			comma = false
 
 		default:
-			return nil, fmt.Errorf("ip/parseIPv4(5): Invalid IP address %s: invalid character %b", ip, b)
+			return [4][]byte{}, fmt.Errorf("ip/parseIPv4(5): Invalid IP address %s: invalid character %b", ip, b)
 		}
 	}
 
@@ -196,23 +230,10 @@ func parseIPv4(ip string) ([]net.IP, error) {
 	case stateCIDR:
 
 	default:
-		return nil, fmt.Errorf("ip/parseIPv4(6): Invalid IP address %s", ip)
-	}
-
-	var ips []net.IP
-
-	// Create a list of IPs
-	for _, o1 := range octets[0] {
-		for _, o2 := range octets[1] {
-			for _, o3 := range octets[2] {
-				for _, o4 := range octets[3] {
-					ips = append(ips, net.IPv4(o1, o2, o3, o4))
-				}
-			}
-		}
+		return [4][]byte{}, fmt.Errorf("ip/parseIPv4(6): Invalid IP address %s", ip)
 	}
 
-	return ips, nil
+	return octets, nil
 }
 
 func parseIPv4CIDR(ips []net.IP, mask net.IPMask) ([]net.IP, error) {
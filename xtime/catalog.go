@@ -0,0 +1,146 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import "fmt"
+
+// Family is a named group of the time formats of one kind of log.
+type Family struct {
+	Name    string
+	Formats []string
+}
+
+// catalog is the list of known log time format families, see Catalog.
+var catalog = []Family{
+	// Kubernetes and other glog based logs, with the severity first
+	{"klog", []string{
+		"I0102 15:04:05.000000",
+		"W0102 15:04:05.000000",
+		"E0102 15:04:05.000000",
+		"F0102 15:04:05.000000",
+	}},
+
+	{"rfc3164", []string{"Jan _2 15:04:05"}},
+	{"rfc5424", []string{"2006-01-02T15:04:05.999999999Z07:00"}},
+
+	// The log package of Go, with log.LstdFlags and log.Lmicroseconds
+	{"golog", []string{
+		"2006/01/02 15:04:05",
+		"2006/01/02 15:04:05.000000",
+	}},
+
+	// The default asctime of the Python logging module
+	{"python", []string{"2006-01-02 15:04:05,000"}},
+
+	// The ISO8601 date format of log4j and logback
+	{"log4j", []string{
+		"2006-01-02 15:04:05,000",
+		"2006-01-02T15:04:05,000",
+	}},
+
+	{"nginx", []string{
+		"2006/01/02 15:04:05",        // Error log
+		"_2/Jan/2006:15:04:05 -0700", // Access log
+	}},
+
+	{"apache", []string{
+		"Mon Jan 02 15:04:05.000000 2006", // Error log
+		"_2/Jan/2006:15:04:05 -0700",      // Access log
+	}},
+
+	// Windows event logs, as exported by the event viewer, and in XML
+	{"windows", []string{
+		"1/2/2006 3:04:05 PM",
+		"2006-01-02T15:04:05.999999999Z07:00",
+	}},
+
+	// The MySQL slow query log, before and after 5.7
+	{"mysql", []string{
+		"# Time: 060102 15:04:05",
+		"060102 15:04:05",
+		"# Time: 2006-01-02T15:04:05.999999999Z07:00",
+	}},
+
+	// AWS load balancer logs, and the separate date and time fields of CloudFront
+	{"aws", []string{
+		"2006-01-02T15:04:05.999999999Z07:00",
+		"2006-01-02\t15:04:05",
+	}},
+
+	{"postgres", []string{
+		"2006-01-02 15:04:05.000 MST",
+		"2006-01-02 15:04:05 MST",
+	}},
+}
+
+// Catalog returns a copy of the list of known log time format families, which can be
+// added to a parser by name with AddFamily.
+func Catalog() []Family {
+	fams := make([]Family, len(catalog))
+
+	for i, fam := range catalog {
+		fams[i] = Family{Name: fam.Name, Formats: append([]string(nil), fam.Formats...)}
+	}
+
+	return fams
+}
+
+// CatalogFormats returns the formats of the named families of the Catalog, in the
+// order given and without duplicates.
+func CatalogFormats(names ...string) ([]string, error) {
+	var (
+		formats []string
+		seen    = make(map[string]bool)
+	)
+
+	for _, name := range names {
+		var fam *Family
+		for i := range catalog {
+			if catalog[i].Name == name {
+				fam = &catalog[i]
+				break
+			}
+		}
+
+		if fam == nil {
+			return nil, fmt.Errorf("xtime/CatalogFormats: Unknown format family %q", name)
+		}
+
+		for _, f := range fam.Formats {
+			if !seen[f] {
+				seen[f] = true
+				formats = append(formats, f)
+			}
+		}
+	}
+
+	return formats, nil
+}
+
+// AddFamily adds the formats of the named families of the Catalog to the parser.
+func (p *Parser) AddFamily(names ...string) error {
+	formats, err := CatalogFormats(names...)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range formats {
+		if err := p.Add(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// catalogFixtures are real log lines for each family, with the time they start with.
+var catalogFixtures = map[string][]struct {
+	line string
+	exp  time.Time
+}{
+	"klog": {
+		{"I0212 10:00:00.123456   12345 main.go:42] started", time.Date(0, 2, 12, 10, 0, 0, 123456000, time.UTC)},
+		{"E0212 10:00:00.123456    7 reflector.go:138] failed", time.Date(0, 2, 12, 10, 0, 0, 123456000, time.UTC)},
+	},
+	"rfc3164": {
+		{"Feb 12 10:00:00 myhost sshd[123]: accepted", time.Date(0, 2, 12, 10, 0, 0, 0, time.UTC)},
+	},
+	"rfc5424": {
+		{"2024-02-12T10:00:00.123456+01:00 myhost app 123 ID47 - started", time.Date(2024, 2, 12, 9, 0, 0, 123456000, time.UTC)},
+	},
+	"golog": {
+		{"2024/02/12 10:00:00 started", time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC)},
+		{"2024/02/12 10:00:00.123456 main.go:42: started", time.Date(2024, 2, 12, 10, 0, 0, 123456000, time.UTC)},
+	},
+	"python": {
+		{"2024-02-12 10:00:00,123 - app - INFO - started", time.Date(2024, 2, 12, 10, 0, 0, 123000000, time.UTC)},
+	},
+	"log4j": {
+		{"2024-02-12 10:00:00,123 INFO [main] App - started", time.Date(2024, 2, 12, 10, 0, 0, 123000000, time.UTC)},
+		{"2024-02-12T10:00:00,123 INFO [main] App - started", time.Date(2024, 2, 12, 10, 0, 0, 123000000, time.UTC)},
+	},
+	"nginx": {
+		{"2024/02/12 10:00:00 [error] 123#0: *1 open() failed", time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC)},
+		{"12/Feb/2024:10:00:00 +0100", time.Date(2024, 2, 12, 9, 0, 0, 0, time.UTC)},
+	},
+	"apache": {
+		{"Mon Feb 12 10:00:00.123456 2024 [core:error] [pid 123] failed", time.Date(2024, 2, 12, 10, 0, 0, 123456000, time.UTC)},
+	},
+	"windows": {
+		{"2/12/2024 10:00:00 AM", time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC)},
+		{"2024-02-12T10:00:00.1234567Z", time.Date(2024, 2, 12, 10, 0, 0, 123456700, time.UTC)},
+	},
+	"mysql": {
+		{"# Time: 240212 10:00:00", time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC)},
+		{"240212  9:00:00", time.Date(2024, 2, 12, 9, 0, 0, 0, time.UTC)},
+		{"# Time: 2024-02-12T10:00:00.123456Z", time.Date(2024, 2, 12, 10, 0, 0, 123456000, time.UTC)},
+	},
+	"aws": {
+		{"2024-02-12T10:00:00.123456Z app/my-lb/50dc6c495c0c9188 10.0.0.1:2817", time.Date(2024, 2, 12, 10, 0, 0, 123456000, time.UTC)},
+		{"2024-02-12\t10:00:00\tLAX1\t392\t10.0.0.1", time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC)},
+	},
+	"postgres": {
+		{"2024-02-12 10:00:00.123 UTC [123] LOG:  started", time.Date(2024, 2, 12, 10, 0, 0, 123000000, time.UTC)},
+		{"2024-02-12 11:00:00 CET [123] LOG:  started", time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC)},
+	},
+}
+
+func TestCatalog(t *testing.T) {
+	for _, fam := range Catalog() {
+		fixtures := catalogFixtures[fam.Name]
+		require.NotEmpty(t, fixtures, fam.Name)
+
+		p := NewParser()
+		require.NoError(t, p.AddFamily(fam.Name), fam.Name)
+		require.Equal(t, fam.Formats, p.Formats())
+
+		for _, f := range fixtures {
+			tm, n, err := p.ParsePrefix(f.line)
+			require.NoError(t, err, f.line)
+			require.Equal(t, f.exp.UnixNano(), tm.UnixNano(), f.line)
+			require.True(t, n == len(f.line) || f.line[n] == ' ' || f.line[n] == '\t', f.line)
+		}
+	}
+}
+
+func TestCatalogFormats(t *testing.T) {
+	formats, err := CatalogFormats("python", "log4j")
+	require.NoError(t, err)
+	require.Equal(t, []string{"2006-01-02 15:04:05,000", "2006-01-02T15:04:05,000"}, formats)
+
+	_, err = CatalogFormats("python", "unknown")
+	require.Error(t, err)
+
+	p := NewParser(TimeFormats...)
+	require.Error(t, p.AddFamily("unknown"))
+	require.NoError(t, p.AddFamily("klog", "mysql"))
+
+	res, err := p.Detect("I0212 10:00:00.123456")
+	require.NoError(t, err)
+	require.Equal(t, "I0102 15:04:05.000000", res.Layout)
+	require.False(t, res.Ambiguous)
+}
+
+func TestCatalogCopy(t *testing.T) {
+	fams := Catalog()
+	fams[0].Name = "changed"
+	fams[0].Formats[0] = "changed"
+
+	require.Equal(t, "klog", Catalog()[0].Name)
+	require.Equal(t, "I0102 15:04:05.000000", Catalog()[0].Formats[0])
+	require.Equal(t, len(catalog), len(Catalog()))
+}
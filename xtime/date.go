@@ -0,0 +1,212 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DateOrder is the order of the day, month and year in numeric dates such as
+// 03/02/2024, which could be either March 2 or February 3.
+type DateOrder int
+
+const (
+	// DateOrderAuto reads numeric dates in the order of the format that matched,
+	// such as month first for "01/02/2006", unless a field is out of range for that
+	// order, such as 13/02/2024, in which case another order is used.
+	DateOrderAuto DateOrder = iota
+	DMY
+	MDY
+	YMD
+
+	numDateOrders
+)
+
+var (
+	// The fields of each order
+	dateOrderFields = [numDateOrders][3]byte{
+		DMY: {'D', 'M', 'Y'},
+		MDY: {'M', 'D', 'Y'},
+		YMD: {'Y', 'M', 'D'},
+	}
+)
+
+func (d DateOrder) String() string {
+	switch d {
+	case DateOrderAuto:
+		return "auto"
+	case DMY:
+		return "DMY"
+	case MDY:
+		return "MDY"
+	case YMD:
+		return "YMD"
+	}
+
+	return fmt.Sprintf("DateOrder(%d)", int(d))
+}
+
+// dateLayouts are the layouts of a format with a numeric date, with its day, month
+// and year fields rearranged in each of the date orders.
+type dateLayouts struct {
+	native  DateOrder             // The order of the format itself
+	layouts [numDateOrders]string // The layout for each order, empty if the format can't be read that way
+}
+
+// newDateLayouts returns the date layouts of the format, or nil if it doesn't have
+// a numeric day and month whose order could be mistaken. The year stays where the
+// format has it, so only the day and month can swap, and a year first format such
+// as 06-01-02 is only read year, month, day.
+func newDateLayouts(elems []layoutElem) *dateLayouts {
+	var (
+		pos    []int  // Positions of the date fields in elems
+		fields []byte // The date fields in the order of the format
+	)
+
+	for i, e := range elems {
+		var f byte
+
+		switch e.kind {
+		case elemLongYear, elemYear:
+			f = 'Y'
+		case elemNumMonth, elemZeroMonth:
+			f = 'M'
+		case elemDay, elemZeroDay, elemUnderDay:
+			f = 'D'
+		default:
+			continue
+		}
+
+		for _, x := range fields {
+			if x == f {
+				// Repeated fields, not a plain date
+				return nil
+			}
+		}
+
+		pos = append(pos, i)
+		fields = append(fields, f)
+	}
+
+	if !strings.ContainsRune(string(fields), 'M') || !strings.ContainsRune(string(fields), 'D') {
+		return nil
+	}
+
+	for i := 1; i < len(pos); i++ {
+		if pos[i] == pos[i-1]+1 {
+			// Compact dates such as 0102 or 20060102 have a fixed order
+			return nil
+		}
+	}
+
+	d := &dateLayouts{native: DateOrderAuto}
+
+	for order := DMY; order < numDateOrders; order++ {
+		var want []byte
+		for _, f := range dateOrderFields[order] {
+			for _, x := range fields {
+				if x == f {
+					want = append(want, f)
+				}
+			}
+		}
+
+		if string(want) == string(fields) {
+			d.native = order
+		}
+
+		if strings.IndexByte(string(want), 'Y') != strings.IndexByte(string(fields), 'Y') {
+			// Moves the year
+			continue
+		}
+
+		d.layouts[order] = dateLayout(elems, pos, want)
+	}
+
+	if d.native == DateOrderAuto {
+		// Such as year, day, month, which isn't one of the orders
+		return nil
+	}
+
+	n := 0
+	for _, l := range d.layouts {
+		if l != "" {
+			n++
+		}
+	}
+
+	if n < 2 {
+		// Only one way to read the date, such as 2006-01-02 or 06-01-02 with the
+		// year first
+		return nil
+	}
+
+	return d
+}
+
+// dateLayout returns the layout with the date fields at pos replaced by the fields
+// in want, keeping the width of each position, or empty if a field doesn't fit.
+func dateLayout(elems []layoutElem, pos []int, want []byte) string {
+	elems = append([]layoutElem(nil), elems...)
+
+	for i, p := range pos {
+		var (
+			kind  = elems[p].kind
+			fixed = kind == elemZeroMonth || kind == elemZeroDay || kind == elemYear
+			value string
+		)
+
+		switch {
+		case want[i] == 'Y' && kind == elemLongYear:
+			value = "2006"
+		case want[i] == 'Y' && fixed:
+			value = "06"
+		case want[i] == 'M' && fixed:
+			value = "01"
+		case want[i] == 'M' && (kind == elemNumMonth || kind == elemDay):
+			value = "1"
+		case want[i] == 'D' && fixed:
+			value = "02"
+		case want[i] == 'D' && (kind == elemNumMonth || kind == elemDay):
+			value = "2"
+		case want[i] == 'D' && kind == elemUnderDay:
+			value = "_2"
+		default:
+			return ""
+		}
+
+		elems[p].value = value
+	}
+
+	var layout string
+	for _, e := range elems {
+		layout += e.value
+	}
+
+	// Make sure the fields didn't merge with the literals around them
+	check := parseLayout(layout)
+	if len(check) != len(elems) {
+		return ""
+	}
+
+	for i := range check {
+		if check[i].value != elems[i].value {
+			return ""
+		}
+	}
+
+	return layout
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateLayouts(t *testing.T) {
+	d := newDateLayouts(parseLayout("01/02/2006 15:04:05"))
+	require.NotNil(t, d)
+	require.Equal(t, MDY, d.native)
+	require.Equal(t, "02/01/2006 15:04:05", d.layouts[DMY])
+	require.Equal(t, "01/02/2006 15:04:05", d.layouts[MDY])
+	require.Equal(t, "", d.layouts[YMD])
+
+	// The year doesn't move
+	d = newDateLayouts(parseLayout("01/02/06 15:04:05"))
+	require.NotNil(t, d)
+	require.Equal(t, "02/01/06 15:04:05", d.layouts[DMY])
+	require.Equal(t, "", d.layouts[YMD])
+
+	// Only one way to read a year first date
+	require.Nil(t, newDateLayouts(parseLayout("06-01-02 15:04:05")))
+	require.Nil(t, newDateLayouts(parseLayout("2006/01/02 15:04:05")))
+	require.Nil(t, newDateLayouts(parseLayout("2006/1/2")))
+
+	d = newDateLayouts(parseLayout("1/2/2006 3:04:05 PM"))
+	require.NotNil(t, d)
+	require.Equal(t, "2/1/2006 3:04:05 PM", d.layouts[DMY])
+
+	require.Nil(t, newDateLayouts(parseLayout("Jan _2 15:04:05")))
+	require.Nil(t, newDateLayouts(parseLayout("15:04:05,000")))
+	require.Nil(t, newDateLayouts(parseLayout("I0102 15:04:05.000000")))
+	require.Nil(t, newDateLayouts(parseLayout("060102 15:04:05")))
+	require.Nil(t, newDateLayouts(parseLayout("2006-01-02 15:04:05")))
+}
+
+func TestDateOrder(t *testing.T) {
+	// Month first, the order of the format
+	res, err := Detect("03/02/2024 10:00:00")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC), res.Time)
+	require.Equal(t, "01/02/2006 15:04:05", res.Layout)
+	require.True(t, res.Ambiguous)
+
+	res, err = Detect("03/02/2024 10:00:00", WithDateOrder(DMY))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 3, 10, 0, 0, 0, time.UTC), res.Time)
+	require.Equal(t, "02/01/2006 15:04:05", res.Layout)
+	require.True(t, res.Ambiguous)
+
+	// Only valid day first
+	res, err = Detect("13/02/2024 10:00:00")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 13, 10, 0, 0, 0, time.UTC), res.Time)
+	require.False(t, res.Ambiguous)
+
+	res, err = Detect("2/13/2024 3:04:05 PM", WithDateOrder(DMY))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 13, 15, 4, 5, 0, time.UTC), res.Time)
+	require.False(t, res.Ambiguous)
+
+	// The same date either way
+	res, err = Detect("05/05/2024 10:00:00", WithDateOrder(DMY))
+	require.NoError(t, err)
+	require.False(t, res.Ambiguous)
+
+	// The 4 digit year can't move, so YMD falls back to the format's order
+	res, err = Detect("03/02/2024 10:00:00", WithDateOrder(YMD))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC), res.Time)
+
+	// Year first is never ambiguous with a 4 digit year
+	res, err = Detect("2024-03-02 10:00:00", WithDateOrder(DMY))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC), res.Time)
+	require.False(t, res.Ambiguous)
+
+	// Two digit years first aren't ambiguous either
+	p := NewParser("06-01-02 15:04:05")
+
+	res, err = p.Detect("06-01-02 15:04:05")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), res.Time)
+	require.False(t, res.Ambiguous)
+
+	res, err = p.Detect("06-01-02 15:04:05", WithDateOrder(DMY))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), res.Time)
+	require.False(t, res.Ambiguous)
+
+	_, err = Detect("13/13/2024 10:00:00")
+	require.Error(t, err)
+
+	p = NewParser(TimeFormats...)
+	p.SetOptions(WithDateOrder(DMY))
+
+	tm, err := p.Parse("03/02/2024 10:00:00")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 3, 10, 0, 0, 0, time.UTC), tm)
+
+	require.Equal(t, "DMY", DMY.String())
+	require.Equal(t, "auto", DateOrderAuto.String())
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	for i, f := range TimeFormats {
+		tx := re2.ReplaceAllString(re1.ReplaceAllString(f, " "), "+")
+		res, err := Detect(tx)
+		require.NoError(t, err)
+		require.Equal(t, i, res.Index, f)
+		require.Equal(t, f, res.Layout)
+	}
+
+	tm, layout, err := ParseFormat("2015-02-06 15:45:16")
+	require.NoError(t, err)
+	require.Equal(t, "2006-01-02 15:04:05", layout)
+	require.Equal(t, time.Date(2015, 2, 6, 15, 45, 16, 0, time.UTC).UnixNano(), tm.UnixNano())
+
+	res, err := Detect("not a time")
+	require.Error(t, err)
+	require.Equal(t, -1, res.Index)
+	require.Equal(t, "", res.Layout)
+}
+
+func TestDetectAll(t *testing.T) {
+	all := DetectAll("2015-02-06 15:45:16")
+	require.Len(t, all, 1)
+	require.Equal(t, "2006-01-02 15:04:05", all[0].Layout)
+
+	// The time package accepts fractional seconds after the seconds, so the
+	// formats without them also match
+	var layouts []string
+	for _, res := range DetectAll("Feb  6 15:45:16.123") {
+		layouts = append(layouts, res.Layout)
+		require.Equal(t, 123000000, res.Time.Nanosecond())
+	}
+
+	require.Equal(t, []string{"Jan _2 15:04:05", "Jan _2 15:04:05.000"}, layouts)
+
+	require.Empty(t, DetectAll("not a time"))
+}
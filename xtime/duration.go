@@ -0,0 +1,163 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDuration parses a duration. It accepts everything time.ParseDuration does, as
+// well as days and weeks, which are 24 and 168 hours long, unit names such as "1.5 hours"
+// or "2 days and 3 hours", spaces between the amounts, such as "1h 30m 15s 500ms", and
+// clock style durations, such as "00:05:30" or "1:30:00.5". Months and years aren't
+// accepted as they have no fixed length.
+func ParseDuration(s string) (time.Duration, error) {
+	t := strings.ToLower(strings.TrimSpace(s))
+
+	sign := time.Duration(1)
+	if t != "" && (t[0] == '-' || t[0] == '+') {
+		if t[0] == '-' {
+			sign = -1
+		}
+
+		t = strings.TrimSpace(t[1:])
+	}
+
+	if t == "0" {
+		return 0, nil
+	}
+
+	if strings.IndexByte(t, ':') != -1 {
+		d, ok := parseClockDuration(t)
+		if !ok {
+			return 0, fmt.Errorf("xtime/ParseDuration: Invalid duration %q", s)
+		}
+
+		return sign * d, nil
+	}
+
+	// Up to 1<<63 nanoseconds for the smallest negative duration
+	var (
+		max    = uint64(maxDuration)
+		total  uint64
+		months bool
+	)
+
+	if sign < 0 {
+		max++
+	}
+
+	ok := parseAmounts(t, func(v uint64, frac string, u timeUnit) bool {
+		if u.d == 0 {
+			months = true
+			return false
+		}
+
+		d, ok := unitDuration(v, frac, u.d, max-total)
+		total += d
+
+		return ok
+	})
+
+	switch {
+	case months:
+		return 0, fmt.Errorf("xtime/ParseDuration: Months and years have no fixed length in %q", s)
+	case !ok:
+		return 0, fmt.Errorf("xtime/ParseDuration: Invalid duration %q", s)
+	case sign < 0:
+		return time.Duration(-total), nil
+	}
+
+	return time.Duration(total), nil
+}
+
+// parseClockDuration parses hours, minutes and seconds separated by colons, with an
+// optional fraction of a second. Only the hours can have more than 2 digits.
+func parseClockDuration(s string) (time.Duration, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+
+	sec, frac := parts[2], ""
+	if k := strings.IndexByte(sec, '.'); k != -1 {
+		sec, frac = sec[:k], sec[k+1:]
+		if frac == "" || !allDigits(frac) {
+			return 0, false
+		}
+	}
+
+	h, ok := atoi(parts[0])
+	if !ok || parts[0] == "" || time.Duration(h) > maxDuration/time.Hour-1 {
+		return 0, false
+	}
+
+	var ms [2]int
+	for i, p := range []string{parts[1], sec} {
+		if len(p) != 2 {
+			return 0, false
+		}
+
+		if ms[i], ok = atoi(p); !ok || ms[i] > 59 {
+			return 0, false
+		}
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(ms[0])*time.Minute +
+		time.Duration(ms[1])*time.Second + fracDuration(frac, time.Second), true
+}
+
+// FormatDuration formats a duration like time.Duration.String, but with days, such as
+// 1d2h30m15.5s. Durations shorter than a second are formatted like time.Duration.String.
+// ParseDuration parses the result back to the same duration.
+func FormatDuration(d time.Duration) string {
+	if d > -time.Second && d < time.Second {
+		return d.String()
+	}
+
+	var buf bytes.Buffer
+
+	// The absolute value, which doesn't fit in a Duration for the smallest one
+	u := uint64(d)
+	if d < 0 {
+		buf.WriteByte('-')
+		u = -u
+	}
+
+	for _, c := range []struct {
+		unit uint64
+		name string
+	}{{uint64(24 * time.Hour), "d"}, {uint64(time.Hour), "h"}, {uint64(time.Minute), "m"}} {
+		if n := u / c.unit; n != 0 {
+			buf.WriteString(strconv.FormatUint(n, 10) + c.name)
+			u -= n * c.unit
+		}
+	}
+
+	if u != 0 {
+		s := strconv.FormatUint(u/uint64(time.Second), 10)
+		if ns := u % uint64(time.Second); ns != 0 {
+			s += strings.TrimRight(fmt.Sprintf(".%09d", ns), "0")
+		}
+
+		buf.WriteString(s + "s")
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected time.Duration
+	}{
+		{"0", 0},
+		{"1d", 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"1h30m15s500ms", time.Hour + 30*time.Minute + 15*time.Second + 500*time.Millisecond},
+		{"1h 30m 15s 500ms", time.Hour + 30*time.Minute + 15*time.Second + 500*time.Millisecond},
+		{"1.5 hours", 90 * time.Minute},
+		{"2 days and 3 hours", 51 * time.Hour},
+		{"1 Hour, 5 Minutes", 65 * time.Minute},
+		{"1.5d", 36 * time.Hour},
+		{"-1.5h", -90 * time.Minute},
+		{"+15s", 15 * time.Second},
+		{"300ms", 300 * time.Millisecond},
+		{"2us", 2 * time.Microsecond},
+		{"2µs", 2 * time.Microsecond},
+		{"00:05:30", 5*time.Minute + 30*time.Second},
+		{"1:30:00.5", 90*time.Minute + 500*time.Millisecond},
+		{"100:00:00", 100 * time.Hour},
+		{"-00:00:01", -time.Second},
+	}
+
+	for _, tt := range tests {
+		actual, err := ParseDuration(tt.in)
+		require.NoError(t, err, tt.in)
+		require.Equal(t, tt.expected, actual, tt.in)
+	}
+
+	for _, in := range []string{"", "-", "1", "1x", "h", "1 month", "2y", "00:05", "00:60:00", "00:5:30", ":05:30", "00:05:30.", "1:2:3:4", "99999999999h", "999999999w",
+		"9223372036854775808ns", "2562048h", "2562047h48m", "18446744073709551616ns", "2562047h47m16.854775808s"} {
+		_, err := ParseDuration(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestParseDurationGo(t *testing.T) {
+	// Everything time.ParseDuration accepts
+	for _, in := range []string{"300ms", "-1.5h", "2h45m", "1.000000001s", "0.5us", "1h1m1s1ms1us1ns", ".5s", "5.s",
+		"10000000000ns", "1500000000ms", "9223372036854775807ns", "-9223372036854775808ns", "2562047h47m16.854775807s",
+		"0000000000000000000001s", "1.00000000000000000001s"} {
+		expected, err := time.ParseDuration(in)
+		require.NoError(t, err, in)
+
+		actual, err := ParseDuration(in)
+		require.NoError(t, err, in)
+		require.Equal(t, expected, actual, in)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in       time.Duration
+		expected string
+	}{
+		{0, "0s"},
+		{500 * time.Millisecond, "500ms"},
+		{time.Second, "1s"},
+		{90 * time.Minute, "1h30m"},
+		{26*time.Hour + 30*time.Minute + 15500*time.Millisecond, "1d2h30m15.5s"},
+		{-36 * time.Hour, "-1d12h"},
+		{14 * 24 * time.Hour, "14d"},
+		{time.Minute + 1, "1m0.000000001s"},
+		{math.MaxInt64, "106751d23h47m16.854775807s"},
+		{math.MinInt64, "-106751d23h47m16.854775808s"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, FormatDuration(tt.in), tt.in.String())
+
+		d, err := ParseDuration(tt.expected)
+		require.NoError(t, err, tt.expected)
+		require.Equal(t, tt.in, d, tt.expected)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// EpochUnit is the unit of a numeric epoch timestamp, such as 1696000000.
+type EpochUnit int
+
+const (
+	// EpochAuto infers the unit from the magnitude of the number. Numbers with up to
+	// 11 digits are seconds, up to 14 milliseconds, up to 17 microseconds, and
+	// anything larger nanoseconds.
+	EpochAuto EpochUnit = iota
+	EpochSeconds
+	EpochMilliseconds
+	EpochMicroseconds
+	EpochNanoseconds
+)
+
+var (
+	// The default range of plausible epoch timestamps, see WithEpochRange.
+	defaultEpochMin = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	defaultEpochMax = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Nanoseconds per unit
+	epochUnitNanos = [...]int64{
+		EpochSeconds:      1e9,
+		EpochMilliseconds: 1e6,
+		EpochMicroseconds: 1e3,
+		EpochNanoseconds:  1,
+	}
+)
+
+func (u EpochUnit) String() string {
+	switch u {
+	case EpochAuto:
+		return "auto"
+	case EpochSeconds:
+		return "s"
+	case EpochMilliseconds:
+		return "ms"
+	case EpochMicroseconds:
+		return "us"
+	case EpochNanoseconds:
+		return "ns"
+	}
+
+	return fmt.Sprintf("EpochUnit(%d)", int(u))
+}
+
+// parseEpoch parses a bare integer or decimal epoch timestamp, such as 1696000000,
+// 1696000000123 or 1696000000.123456, and returns the time and the unit used.
+func parseEpoch(t string, o *options) (time.Time, EpochUnit, error) {
+	var (
+		intPart, fracPart string
+		dot               = -1
+	)
+
+	for i := 0; i < len(t); i++ {
+		if t[i] == '.' && dot == -1 && i > 0 {
+			dot = i
+		} else if !isDigit(t[i]) {
+			return time.Time{}, EpochAuto, fmt.Errorf("Unknown time format")
+		}
+	}
+
+	intPart = t
+	if dot != -1 {
+		intPart, fracPart = t[:dot], t[dot+1:]
+	}
+
+	if intPart == "" || len(intPart) > 19 || (dot != -1 && fracPart == "") {
+		return time.Time{}, EpochAuto, fmt.Errorf("xtime/parseEpoch: Invalid epoch timestamp %s", t)
+	}
+
+	var n int64
+	for i := 0; i < len(intPart); i++ {
+		d := int64(intPart[i] - '0')
+		if n > (math.MaxInt64-d)/10 {
+			return time.Time{}, EpochAuto, fmt.Errorf("xtime/parseEpoch: Invalid epoch timestamp %s", t)
+		}
+
+		n = n*10 + d
+	}
+
+	unit := o.epochUnit
+	if unit < EpochAuto || unit > EpochNanoseconds {
+		return time.Time{}, EpochAuto, fmt.Errorf("xtime/parseEpoch: Invalid epoch unit %s", unit)
+	}
+
+	if unit == EpochAuto {
+		switch {
+		case n < 1e11:
+			unit = EpochSeconds
+		case n < 1e14:
+			unit = EpochMilliseconds
+		case n < 1e17:
+			unit = EpochMicroseconds
+		default:
+			unit = EpochNanoseconds
+		}
+	}
+
+	var (
+		per  = epochUnitNanos[unit]
+		div  = int64(1e9) / per // Units per second
+		sec  = n / div
+		nsec = (n % div) * per
+	)
+
+	// The fraction of a unit, truncated to nanoseconds
+	for i, scale := 0, per/10; i < len(fracPart) && scale > 0; i, scale = i+1, scale/10 {
+		nsec += int64(fracPart[i]-'0') * scale
+	}
+
+	tm := time.Unix(sec, nsec).In(o.loc)
+
+	if tm.Before(o.epochMin) || !tm.Before(o.epochMax) {
+		return time.Time{}, unit, fmt.Errorf("xtime/parseEpoch: Epoch timestamp %s out of range as %s", t, unit)
+	}
+
+	return tm, unit, nil
+}
+
+// epochResult returns the result of the epoch timestamp t, which has all the fields
+// and an explicit zone.
+func epochResult(t string, tm time.Time, unit EpochUnit) Result {
+	res := Result{Time: tm, Index: -1, Epoch: unit, Fields: FieldDate | FieldTime | FieldZone, Zone: ZoneExplicit}
+
+	if tm.Nanosecond() != 0 {
+		res.Fields |= FieldFraction
+	}
+
+	// The digits of the unit, and of its fraction
+	switch unit {
+	case EpochMilliseconds:
+		res.Precision = 3
+	case EpochMicroseconds:
+		res.Precision = 6
+	case EpochNanoseconds:
+		res.Precision = 9
+	}
+
+	if k := strings.IndexByte(t, '.'); k != -1 {
+		res.Precision += len(t) - k - 1
+	}
+
+	if res.Precision > 9 {
+		res.Precision = 9
+	}
+
+	return res
+}
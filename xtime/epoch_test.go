@@ -0,0 +1,89 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEpoch(t *testing.T) {
+	tests := []struct {
+		in    string
+		unit  EpochUnit
+		nanos int64
+	}{
+		{"1696000000", EpochSeconds, 1696000000e9},
+		{"1696000000.5", EpochSeconds, 1696000000500000000},
+		{"1696000000.123456", EpochSeconds, 1696000000123456000},
+		{"1696000000.1234567891", EpochSeconds, 1696000000123456789},
+		{"1696000000123", EpochMilliseconds, 1696000000123000000},
+		{"1696000000123.5", EpochMilliseconds, 1696000000123500000},
+		{"1696000000123456", EpochMicroseconds, 1696000000123456000},
+		{"1696000000123456789", EpochNanoseconds, 1696000000123456789},
+	}
+
+	for _, tt := range tests {
+		res, err := Detect(tt.in)
+		require.NoError(t, err, tt.in)
+		require.Equal(t, tt.unit, res.Epoch, tt.in)
+		require.Equal(t, tt.nanos, res.Time.UnixNano(), tt.in)
+		require.Equal(t, -1, res.Index)
+		require.Equal(t, time.UTC, res.Time.Location())
+	}
+
+	// Forced unit
+	actual, err := ParseWith("1696000000", WithEpochUnit(EpochMilliseconds), WithEpochRange(time.Time{}, defaultEpochMax))
+	require.NoError(t, err)
+	require.Equal(t, int64(1696000000e6), actual.UnixNano())
+
+	// Out of the plausible range
+	_, err = Parse("42")
+	require.Error(t, err)
+
+	_, err = ParseWith("1696000000", WithEpochUnit(EpochMilliseconds))
+	require.Error(t, err)
+
+	// Invalid units
+	for _, unit := range []EpochUnit{EpochUnit(42), EpochUnit(-1)} {
+		_, err = ParseWith("1696000000", WithEpochUnit(unit))
+		require.Error(t, err, unit.String())
+
+		_, err = Detect("1696000000", WithEpochUnit(unit))
+		require.Error(t, err, unit.String())
+
+		_, _, err = ParsePrefix("1696000000 started", WithEpochUnit(unit))
+		require.Error(t, err, unit.String())
+
+		_, err = NewSession(WithEpochUnit(unit)).Parse("1696000000")
+		require.Error(t, err, unit.String())
+	}
+
+	actual, err = ParseWith("42", WithEpochRange(time.Unix(0, 0), defaultEpochMax))
+	require.NoError(t, err)
+	require.Equal(t, int64(42), actual.Unix())
+
+	loc := time.FixedZone("", 3600)
+	actual, err = ParseInLocation("1696000000", loc)
+	require.NoError(t, err)
+	require.Equal(t, loc, actual.Location())
+
+	for _, in := range []string{"", ".5", "1696000000.", "1696000000.1.2", "-1696000000", "1696000000a", "99999999999999999999"} {
+		_, err = Parse(in)
+		require.Error(t, err, in)
+	}
+}
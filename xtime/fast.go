@@ -0,0 +1,395 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"sync"
+	"time"
+)
+
+// fastFunc converts a time string in one layout without going through time.Parse,
+// and without allocating. It returns false for anything out of the ordinary, in
+// which case time.Parse is used, so it must never accept a time string that
+// time.Parse would reject, or give it a different time.
+type fastFunc func(t string, loc *time.Location) (time.Time, bool)
+
+var (
+	// fastLayouts are the fast converters of the most common layouts.
+	fastLayouts = map[string]fastFunc{
+		"2006-01-02T15:04:05Z07:00":           fastRFC3339,
+		"2006-01-02T15:04:05.999999999Z07:00": fastRFC3339,
+		"2006-01-02 15:04:05":                 fastDateTime(0),
+		"2006-01-02 15:04:05,000":             fastDateTime(3),
+		"2006-01-02 15:04:05.000":             fastDateTime(3),
+		"Jan _2 15:04:05":                     fastSyslog(0),
+		"Jan _2 15:04:05.000":                 fastSyslog(3),
+		"Jan _2 15:04:05.000000":              fastSyslog(6),
+		"Jan _2 15:04:05.000000000":           fastSyslog(9),
+		"_2/Jan/2006:15:04:05 -0700":          fastCLF,
+	}
+
+	// fixedZones are the zones of the numeric offsets, in minutes from -24 to +24
+	// hours, so they are only made once.
+	fixedZones struct {
+		sync.RWMutex
+		zones [2*24*60 + 1]*time.Location
+	}
+)
+
+// fastRFC3339 converts 2006-01-02T15:04:05Z07:00, with optional fractional seconds.
+func fastRFC3339(t string, loc *time.Location) (time.Time, bool) {
+	s := scanner{s: t, ok: true}
+
+	year, month, day := s.date()
+	s.lit('T')
+	hour, min, sec := s.clock(2)
+	nsec := s.autoFrac()
+
+	if !s.ok || s.s == "" {
+		return time.Time{}, false
+	}
+
+	if s.s == "Z" {
+		return s.time(year, month, day, hour, min, sec, nsec, time.UTC)
+	}
+
+	offset := s.offset(true)
+	if !s.ok || s.s != "" {
+		return time.Time{}, false
+	}
+
+	tm, ok := s.time(year, month, day, hour, min, sec, nsec, time.UTC)
+	if !ok {
+		return tm, false
+	}
+
+	return withOffset(tm, offset, loc), true
+}
+
+// fastDateTime converts 2006-01-02 15:04:05, with exactly n digits of fractional
+// seconds, or any number if n is 0.
+func fastDateTime(n int) fastFunc {
+	return func(t string, loc *time.Location) (time.Time, bool) {
+		s := scanner{s: t, ok: true}
+
+		year, month, day := s.date()
+		s.lit(' ')
+		hour, min, sec := s.clock(2)
+
+		var nsec int
+		if n == 0 {
+			nsec = s.autoFrac()
+		} else {
+			nsec = s.frac(n)
+		}
+
+		if s.s != "" {
+			return time.Time{}, false
+		}
+
+		return s.time(year, month, day, hour, min, sec, nsec, loc)
+	}
+}
+
+// fastSyslog converts Jan _2 15:04:05, with exactly n digits of fractional seconds,
+// or any number if n is 0.
+func fastSyslog(n int) fastFunc {
+	return func(t string, loc *time.Location) (time.Time, bool) {
+		s := scanner{s: t, ok: true}
+
+		month := s.month()
+		s.spaces()
+		day := s.num12()
+		s.spaces()
+		hour, min, sec := s.clock(1)
+
+		var nsec int
+		if n == 0 {
+			nsec = s.autoFrac()
+		} else {
+			nsec = s.frac(n)
+		}
+
+		if s.s != "" {
+			return time.Time{}, false
+		}
+
+		return s.time(0, month, day, hour, min, sec, nsec, loc)
+	}
+}
+
+// fastCLF converts the common log format, _2/Jan/2006:15:04:05 -0700.
+func fastCLF(t string, loc *time.Location) (time.Time, bool) {
+	s := scanner{s: t, ok: true}
+
+	if hasPrefix(s.s, " ") {
+		s.s = s.s[1:]
+	}
+
+	day := s.num12()
+	s.lit('/')
+	month := s.month()
+	s.lit('/')
+	year := s.num(4)
+	s.lit(':')
+	hour, min, sec := s.clock(1)
+	nsec := s.autoFrac()
+	s.spaces()
+	offset := s.offset(false)
+
+	if s.s != "" {
+		return time.Time{}, false
+	}
+
+	tm, ok := s.time(year, month, day, hour, min, sec, nsec, time.UTC)
+	if !ok {
+		return tm, false
+	}
+
+	return withOffset(tm, offset, loc), true
+}
+
+// withOffset moves the time, read as UTC, to a zone with the offset in seconds. Like
+// time.Parse, it uses loc if it has the offset at that time.
+func withOffset(t time.Time, offset int, loc *time.Location) time.Time {
+	t = t.Add(-time.Duration(offset) * time.Second)
+
+	if _, off := t.In(loc).Zone(); off == offset {
+		return t.In(loc)
+	}
+
+	return t.In(fixedZone(offset))
+}
+
+// fixedZone returns an unnamed zone with the offset in seconds, the same as
+// time.Parse makes for numeric offsets.
+func fixedZone(offset int) *time.Location {
+	i := offset/60 + 24*60
+	if offset%60 != 0 || i < 0 || i >= len(fixedZones.zones) {
+		return time.FixedZone("", offset)
+	}
+
+	fixedZones.RLock()
+	z := fixedZones.zones[i]
+	fixedZones.RUnlock()
+
+	if z != nil {
+		return z
+	}
+
+	fixedZones.Lock()
+	defer fixedZones.Unlock()
+
+	if fixedZones.zones[i] == nil {
+		fixedZones.zones[i] = time.FixedZone("", offset)
+	}
+
+	return fixedZones.zones[i]
+}
+
+// scanner reads the fields of a time string for the fast converters. Once a field
+// doesn't match, ok is false and the other fields return 0.
+type scanner struct {
+	s  string
+	ok bool
+}
+
+// num reads exactly n digits.
+func (s *scanner) num(n int) int {
+	if !s.ok || len(s.s) < n {
+		s.ok = false
+		return 0
+	}
+
+	v := 0
+	for i := 0; i < n; i++ {
+		if !isDigit(s.s[i]) {
+			s.ok = false
+			return 0
+		}
+
+		v = v*10 + int(s.s[i]-'0')
+	}
+
+	s.s = s.s[n:]
+
+	return v
+}
+
+// num12 reads 1 or 2 digits.
+func (s *scanner) num12() int {
+	if len(s.s) > 1 && isDigit(s.s[1]) {
+		return s.num(2)
+	}
+
+	return s.num(1)
+}
+
+func (s *scanner) lit(c byte) {
+	if !s.ok || len(s.s) == 0 || s.s[0] != c {
+		s.ok = false
+		return
+	}
+
+	s.s = s.s[1:]
+}
+
+// spaces reads one or more spaces.
+func (s *scanner) spaces() {
+	s.lit(' ')
+
+	for len(s.s) > 0 && s.s[0] == ' ' {
+		s.s = s.s[1:]
+	}
+}
+
+// date reads 2006-01-02.
+func (s *scanner) date() (year, month, day int) {
+	year = s.num(4)
+	s.lit('-')
+	month = s.num(2)
+	s.lit('-')
+	day = s.num(2)
+
+	return
+}
+
+// clock reads 15:04:05, with at least minHour digits for the hour.
+func (s *scanner) clock(minHour int) (hour, min, sec int) {
+	if minHour == 1 {
+		hour = s.num12()
+	} else {
+		hour = s.num(2)
+	}
+
+	s.lit(':')
+	min = s.num(2)
+	s.lit(':')
+	sec = s.num(2)
+
+	return
+}
+
+// month reads a 3 letter English month name, ignoring case.
+func (s *scanner) month() int {
+	if !s.ok || len(s.s) < 3 {
+		s.ok = false
+		return 0
+	}
+
+	if m := English.lookup(elemMonth, s.s[:3]); m != -1 {
+		s.s = s.s[3:]
+		return m + 1
+	}
+
+	s.ok = false
+
+	return 0
+}
+
+// frac reads a period or comma followed by exactly n digits, as nanoseconds.
+func (s *scanner) frac(n int) int {
+	if !s.ok || len(s.s) < n+1 || (s.s[0] != '.' && s.s[0] != ',') {
+		s.ok = false
+		return 0
+	}
+
+	s.s = s.s[1:]
+
+	return scaleNanos(s.num(n), n)
+}
+
+// autoFrac reads the optional fractional seconds that time.Parse accepts after the
+// seconds, a period or comma followed by any number of digits.
+func (s *scanner) autoFrac() int {
+	if !s.ok || len(s.s) < 2 || (s.s[0] != '.' && s.s[0] != ',') || !isDigit(s.s[1]) {
+		return 0
+	}
+
+	n := 1
+	for n < len(s.s) && isDigit(s.s[n]) {
+		n++
+	}
+
+	// Only the first 9 digits count
+	d := n - 1
+	if d > 9 {
+		d = 9
+	}
+
+	s.s = s.s[1:]
+	v := s.num(d)
+	s.s = s.s[n-1-d:]
+
+	return scaleNanos(v, d)
+}
+
+// offset reads -07:00 if colon, -0700 otherwise, and returns it in seconds.
+func (s *scanner) offset(colon bool) int {
+	if !s.ok || len(s.s) == 0 || (s.s[0] != '+' && s.s[0] != '-') {
+		s.ok = false
+		return 0
+	}
+
+	sign := 1
+	if s.s[0] == '-' {
+		sign = -1
+	}
+
+	s.s = s.s[1:]
+	hh := s.num(2)
+	if colon {
+		s.lit(':')
+	}
+	mm := s.num(2)
+
+	if hh > 23 || mm > 59 {
+		s.ok = false
+	}
+
+	return sign * (hh*60 + mm) * 60
+}
+
+// time returns the time if all the fields were read and are in range.
+func (s *scanner) time(year, month, day, hour, min, sec, nsec int, loc *time.Location) (time.Time, bool) {
+	if !s.ok || month < 1 || month > 12 || day < 1 || day > daysIn(month, year) || hour > 23 || min > 59 || sec > 59 {
+		return time.Time{}, false
+	}
+
+	return time.Date(year, time.Month(month), day, hour, min, sec, nsec, loc), true
+}
+
+func scaleNanos(v, digits int) int {
+	for ; digits < 9; digits++ {
+		v *= 10
+	}
+
+	return v
+}
+
+func daysIn(month, year int) int {
+	switch month {
+	case 2:
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 29
+		}
+
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+
+	return 31
+}
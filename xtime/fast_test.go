@@ -0,0 +1,136 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var fastInputs = []string{
+	"2015-02-06T15:45:16Z",
+	"2015-02-06T15:45:16.123456789Z",
+	"2015-02-06T15:45:16.1234567891234Z",
+	"2015-02-06T15:45:16,5+08:00",
+	"2015-02-06T15:45:16-07:00",
+	"2015-02-06T15:45:16+00:00",
+	"2015-02-06T15:45:16-00:00",
+	"2015-02-06T15:45:16+24:00",
+	"2015-02-30T15:45:16Z",
+	"2016-02-29T15:45:16Z",
+	"2015-02-06T24:45:16Z",
+	"2015-02-06T15:45:60Z",
+	"2015-13-06T15:45:16Z",
+	"2015-02-06T5:45:16Z",
+	"2015-02-06t15:45:16z",
+	"2015-02-06T15:45:16",
+	"2015-02-06 15:45:16",
+	"2015-02-06 15:45:16.5",
+	"2015-02-06 15:45:16,123",
+	"2015-02-06 15:45:16.123",
+	"2015-02-06 15:45:16,1234",
+	"2015-02-06 15:45:16,12",
+	"2015-02-06  15:45:16",
+	"Feb  6 15:45:16",
+	"feb 6 15:45:16",
+	"Feb 06 5:45:16",
+	"Feb 29 15:45:16",
+	"Feb 30 15:45:16",
+	"Feb  6 15:45:16.123",
+	"Feb  6 15:45:16.123456",
+	"Feb  6 15:45:16.123456789",
+	"Feb  6 15:45:16.1234",
+	"Fev  6 15:45:16",
+	"06/Feb/2015:15:45:16 -0700",
+	" 6/Feb/2015:15:45:16 +0530",
+	"6/FEB/2015:15:45:16 +0000",
+	"06/Feb/2015:15:45:16 -07:00",
+	"06/Feb/2015:15:45:16 +2500",
+}
+
+func TestFastLayouts(t *testing.T) {
+	locs := []*time.Location{time.UTC, time.FixedZone("X", 8*3600), time.FixedZone("Y", -7*3600)}
+
+	for layout, f := range fastLayouts {
+		for _, in := range fastInputs {
+			for _, loc := range locs {
+				tm, ok := f(in, loc)
+				if !ok {
+					continue
+				}
+
+				exp, err := time.ParseInLocation(layout, in, loc)
+				require.NoError(t, err, layout+" "+in)
+				require.Equal(t, exp.UnixNano(), tm.UnixNano(), layout+" "+in)
+				require.Equal(t, exp.Location().String(), tm.Location().String(), layout+" "+in)
+
+				_, expOffset := exp.Zone()
+				_, offset := tm.Zone()
+				require.Equal(t, expOffset, offset, layout+" "+in)
+			}
+		}
+	}
+}
+
+func TestFastParse(t *testing.T) {
+	// Every time string parses the same with and without the fast converters
+	slow := NewParser(TimeFormats...)
+	for i := range slow.fast {
+		slow.fast[i] = nil
+	}
+
+	for _, in := range fastInputs {
+		for _, loc := range []*time.Location{time.UTC, time.FixedZone("X", 8*3600)} {
+			exp, expErr := slow.ParseInLocation(in, loc)
+			tm, err := ParseInLocation(in, loc)
+
+			require.Equal(t, expErr == nil, err == nil, in)
+			require.Equal(t, exp.UnixNano(), tm.UnixNano(), in)
+		}
+	}
+}
+
+func TestFastAllocs(t *testing.T) {
+	for _, in := range []string{
+		"2015-02-06T15:45:16Z",
+		"2015-02-06T15:45:16.123456+08:00",
+		"2015-02-06 15:45:16,123",
+		"Feb  6 15:45:16",
+		"06/Feb/2015:15:45:16 -0700",
+	} {
+		_, err := Parse(in)
+		require.NoError(t, err)
+
+		allocs := testing.AllocsPerRun(100, func() {
+			Parse(in)
+		})
+
+		require.Equal(t, 0.0, allocs, in)
+	}
+}
+
+func BenchmarkParseRFC3339(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Parse("2015-02-06T15:45:16.123456+08:00")
+	}
+}
+
+func BenchmarkParseSyslog(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Parse("Feb  6 15:45:16")
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields is a set of the components of a time, used to report which ones were in
+// the time string, so a date alone isn't mistaken for midnight.
+type Fields uint
+
+const (
+	FieldYear Fields = 1 << iota
+	FieldMonth
+	FieldDay
+	FieldWeekday
+	FieldHour
+	FieldMinute
+	FieldSecond
+	FieldFraction
+	FieldZone
+
+	FieldDate = FieldYear | FieldMonth | FieldDay
+	FieldTime = FieldHour | FieldMinute | FieldSecond
+)
+
+var fieldNames = []string{"year", "month", "day", "weekday", "hour", "minute", "second", "fraction", "zone"}
+
+// Has returns true if f has all the fields of x.
+func (f Fields) Has(x Fields) bool {
+	return f&x == x
+}
+
+func (f Fields) String() string {
+	var names []string
+
+	for i, name := range fieldNames {
+		if f&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, "|")
+}
+
+// layoutFields returns the fields of the layout. A day of the year, or an ISO week
+// and weekday, count as a month and day, since they give the date.
+func layoutFields(layout string, elems []layoutElem) Fields {
+	var f Fields
+
+	for _, e := range elems {
+		switch e.kind {
+		case elemLongYear, elemYear:
+			f |= FieldYear
+		case elemLongMonth, elemMonth, elemNumMonth, elemZeroMonth:
+			f |= FieldMonth
+		case elemDay, elemUnderDay, elemZeroDay:
+			f |= FieldDay
+		case elemUnderYearDay, elemZeroYearDay:
+			f |= FieldMonth | FieldDay
+		case elemLongWeekDay, elemWeekDay:
+			f |= FieldWeekday
+		case elemHour, elemHour12, elemZeroHour12:
+			f |= FieldHour
+		case elemMinute, elemZeroMinute:
+			f |= FieldMinute
+		case elemSecond, elemZeroSecond:
+			f |= FieldSecond
+		case elemFracSecond0, elemFracSecond9:
+			f |= FieldFraction
+		case elemTZ, elemISO8601TZ, elemNumTZ:
+			f |= FieldZone
+		}
+	}
+
+	if _, ok := customLayouts[layout]; ok {
+		// The week is in the month, and the weekday in the day
+		f |= FieldWeekday
+	}
+
+	return f
+}
+
+// ZoneKind is how the time zone was given in a time string.
+type ZoneKind int
+
+const (
+	// ZoneDefault is no zone, the time is in the location set with WithLocation.
+	ZoneDefault ZoneKind = iota
+
+	// ZoneExplicit is a numeric offset, such as -0700, Z or GMT+8, or an epoch timestamp.
+	ZoneExplicit
+
+	// ZoneAbbrev is a zone abbreviation, such as PST, see UnknownZone.
+	ZoneAbbrev
+)
+
+func (z ZoneKind) String() string {
+	switch z {
+	case ZoneDefault:
+		return "default"
+	case ZoneExplicit:
+		return "explicit"
+	case ZoneAbbrev:
+		return "abbreviation"
+	}
+
+	return "ZoneKind(" + strconv.Itoa(int(z)) + ")"
+}
+
+// layoutZone returns how the zone of the time t, parsed with the layout, was given.
+// The time package uses the offset if the layout has both an offset and an
+// abbreviation.
+func layoutZone(elems []layoutElem, t time.Time) ZoneKind {
+	z := ZoneDefault
+
+	for _, e := range elems {
+		switch e.kind {
+		case elemISO8601TZ, elemNumTZ:
+			return ZoneExplicit
+		case elemTZ:
+			z = ZoneAbbrev
+		}
+	}
+
+	if name, _ := t.Zone(); z == ZoneAbbrev && len(name) > 3 && hasPrefix(name, "GMT") {
+		return ZoneExplicit
+	}
+
+	return z
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFields(t *testing.T) {
+	for _, c := range []struct {
+		in     string
+		layout string
+		exp    time.Time
+		fields Fields
+	}{
+		{"2024-02-12", "2006-01-02", time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC), FieldDate},
+		{"20240212", "20060102", time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC), FieldDate},
+		{"2024-043", "2006-002", time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC), FieldDate},
+		{"10:30", "15:04", time.Date(0, 1, 1, 10, 30, 0, 0, time.UTC), FieldHour | FieldMinute},
+		{"10:30:15", "15:04:05", time.Date(0, 1, 1, 10, 30, 15, 0, time.UTC), FieldTime},
+		{"10:30:15.5", "15:04:05", time.Date(0, 1, 1, 10, 30, 15, 500000000, time.UTC), FieldTime | FieldFraction},
+		{"20240212T103015Z", "20060102T150405Z0700", time.Date(2024, 2, 12, 10, 30, 15, 0, time.UTC), FieldDate | FieldTime | FieldZone},
+		{"20240212T103015+0100", "20060102T150405Z0700", time.Date(2024, 2, 12, 9, 30, 15, 0, time.UTC), FieldDate | FieldTime | FieldZone},
+		{"Feb  6 15:45:16", "Jan _2 15:04:05", time.Date(0, 2, 6, 15, 45, 16, 0, time.UTC), FieldMonth | FieldDay | FieldTime},
+		{"Mon, 12 Feb 2024 10:00:00 UTC", "Mon, 02 Jan 2006 15:04:05 MST", time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC), FieldDate | FieldWeekday | FieldTime | FieldZone},
+	} {
+		res, err := Detect(c.in)
+		require.NoError(t, err, c.in)
+		require.Equal(t, c.layout, res.Layout, c.in)
+		require.Equal(t, c.exp.UnixNano(), res.Time.UnixNano(), c.in)
+		require.Equal(t, c.fields, res.Fields, c.in)
+	}
+
+	res, err := Detect("1696000000.5")
+	require.NoError(t, err)
+	require.True(t, res.Fields.Has(FieldDate|FieldTime|FieldZone|FieldFraction))
+
+	// Only dates get a year from the reference time
+	ref := time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC)
+
+	tm, err := ParseWith("10:30", WithReference(ref))
+	require.NoError(t, err)
+	require.Equal(t, 0, tm.Year())
+
+	tm, err = ParseWith("Feb  6 15:45:16", WithReference(ref))
+	require.NoError(t, err)
+	require.Equal(t, 2024, tm.Year())
+
+	require.Equal(t, "year|month|day", FieldDate.String())
+	require.Equal(t, "", Fields(0).String())
+	require.False(t, FieldDate.Has(FieldTime))
+}
+
+func TestResultMetadata(t *testing.T) {
+	for _, c := range []struct {
+		in        string
+		precision int
+		zone      ZoneKind
+	}{
+		{"2015-02-06 15:45:16", 0, ZoneDefault},
+		{"2015-02-06 15:45:16,123", 3, ZoneDefault},
+		{"2015-02-06T15:45:16Z", 0, ZoneExplicit},
+		{"2015-02-06T15:45:16.123456+08:00", 6, ZoneExplicit},
+		{"2015-02-06T15:45:16.1+08:00", 1, ZoneExplicit},
+		{"10:30:15.50", 2, ZoneDefault},
+		{"Mon, 12 Feb 2024 10:00:00 PST", 0, ZoneAbbrev},
+		{"Mon, 12 Feb 2024 10:00:00 XYZ", 0, ZoneAbbrev},
+		{"Mon Feb 12 10:00:00 GMT+8 2024", 0, ZoneExplicit},
+		{"1696000000", 0, ZoneExplicit},
+		{"1696000000123", 3, ZoneExplicit},
+		{"1696000000.12", 2, ZoneExplicit},
+		{"1696000000123.456", 6, ZoneExplicit},
+	} {
+		res, err := Detect(c.in)
+		require.NoError(t, err, c.in)
+		require.Equal(t, c.precision, res.Precision, c.in)
+		require.Equal(t, c.zone, res.Zone, c.in)
+		require.False(t, res.YearInferred, c.in)
+	}
+
+	ref := time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC)
+
+	res, err := Detect("Feb  6 15:45:16.250", WithReference(ref))
+	require.NoError(t, err)
+	require.True(t, res.YearInferred)
+	require.Equal(t, 3, res.Precision)
+	require.Equal(t, 2024, res.Time.Year())
+
+	// Other date orders
+	res, err = Detect("03/02/2024 10:00:00.5", WithDateOrder(DMY))
+	require.NoError(t, err)
+	require.True(t, res.Ambiguous)
+	require.Equal(t, 1, res.Precision)
+
+	// Unknown units have no digits of their own
+	require.Equal(t, 1, epochResult("1696000000.5", time.Unix(1696000000, 5e8), EpochUnit(42)).Precision)
+
+	require.Equal(t, "abbreviation", ZoneAbbrev.String())
+	require.Equal(t, "ZoneKind(7)", ZoneKind(7).String())
+}
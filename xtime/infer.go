@@ -0,0 +1,620 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Inference is a layout inferred from sample time strings by Infer.
+type Inference struct {
+	Layout string
+
+	// Confidence is between 0 and 1. It is lower when the samples don't settle
+	// everything, such as 03/02/2024 alone, which could be month or day first.
+	Confidence float64
+
+	// Order is the order of the numeric date fields, DateOrderAuto if there are none.
+	Order DateOrder
+}
+
+type inferKind int
+
+// The fields recognized in the samples.
+const (
+	sampleLiteral     inferKind = iota
+	sampleNum                   // 1 or 2 digits: day, month or 2 digit year
+	sampleYear                  // 2006
+	sampleCompactDate           // 20060102
+	sampleCompactTime           // 150405
+	sampleHour
+	sampleMinute
+	sampleSecond
+	sampleFrac
+	sampleMonth
+	sampleLongMonth
+	sampleWeekDay
+	sampleLongWeekDay
+	samplePM
+	sampleZoneAbbrev
+	sampleZoneOffset // -07, -0700, -07:00 or Z
+)
+
+type inferItem struct {
+	kind inferKind
+	text string
+}
+
+// inferField is one field of the layout, with what all the samples have in it.
+type inferField struct {
+	kind  inferKind
+	texts []string
+	max   int  // Largest value, for numbers
+	short bool // Some value is a single digit
+}
+
+// Infer returns a layout, in the format of the time package, that parses all the
+// sample time strings, such as "13/02/2024 10:00:00.123" for
+// "02/01/2006 15:04:05.000". The field widths, separators, fractional seconds and
+// zone style are taken from the samples, and the order of numeric dates from their
+// values, so samples with days above 12 make for a more confident inference.
+//
+// The samples must all have the same shape, and contain only the time. Use
+// Parser.Learn to add the inferred layout to a parser.
+func Infer(samples []string) (Inference, error) {
+	var fields []inferField
+
+	for _, s := range samples {
+		items, ok := inferItems(strings.TrimSpace(s))
+		if !ok {
+			return Inference{}, fmt.Errorf("xtime/Infer: Unknown time format %q", s)
+		}
+
+		if fields, ok = mergeItems(fields, items); !ok {
+			return Inference{}, fmt.Errorf("xtime/Infer: Samples have different formats, %q", s)
+		}
+	}
+
+	if len(fields) == 0 {
+		return Inference{}, fmt.Errorf("xtime/Infer: No samples")
+	}
+
+	inf, err := inferLayout(fields)
+	if err != nil {
+		return Inference{}, err
+	}
+
+	if !isTimeLayout(parseLayout(inf.Layout)) {
+		return Inference{}, fmt.Errorf("xtime/Infer: Unknown time format %q", samples[0])
+	}
+
+	for _, s := range samples {
+		if _, err := time.Parse(inf.Layout, strings.TrimSpace(s)); err != nil {
+			return Inference{}, fmt.Errorf("xtime/Infer: Inferred layout %q doesn't match %q", inf.Layout, s)
+		}
+	}
+
+	return inf, nil
+}
+
+// Learn infers the layout of the samples, see Infer, and adds it to the parser.
+func (p *Parser) Learn(samples []string) (Inference, error) {
+	inf, err := Infer(samples)
+	if err != nil {
+		return Inference{}, err
+	}
+
+	return inf, p.Add(inf.Layout)
+}
+
+// inferItems splits the time string into fields and literals.
+func inferItems(s string) ([]inferItem, bool) {
+	var (
+		items   []inferItem
+		hasTime bool
+	)
+
+	add := func(kind inferKind, text string) {
+		if kind == sampleLiteral && len(items) > 0 && items[len(items)-1].kind == sampleLiteral {
+			items[len(items)-1].text += text
+			return
+		}
+
+		items = append(items, inferItem{kind, text})
+	}
+
+	last := func() inferKind {
+		if len(items) == 0 {
+			return sampleLiteral
+		}
+
+		return items[len(items)-1].kind
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		j := i + 1
+		for isDigit(c) && j < len(s) && isDigit(s[j]) {
+			j++
+		}
+
+		switch {
+		case isDigit(c) && !hasTime && j-i <= 2 && hasPrefix(s[j:], ":") && matchNum(s[j+1:], true) == 2:
+			// 15:04 or 15:04:05
+			add(sampleHour, s[i:j])
+			add(sampleLiteral, ":")
+			add(sampleMinute, s[j+1:j+3])
+			j += 3
+
+			if hasPrefix(s[j:], ":") && matchNum(s[j+1:], true) == 2 {
+				add(sampleLiteral, ":")
+				add(sampleSecond, s[j+1:j+3])
+				j += 3
+			}
+
+			hasTime = true
+
+		case isDigit(c) && j-i == 14:
+			add(sampleCompactDate, s[i:i+8])
+			add(sampleCompactTime, s[i+8:j])
+			hasTime = true
+
+		case isDigit(c) && j-i == 8 && !hasTime:
+			add(sampleCompactDate, s[i:j])
+
+		case isDigit(c) && j-i == 6 && !hasTime && len(items) > 0 && items[len(items)-1].text == "T":
+			add(sampleCompactTime, s[i:j])
+			hasTime = true
+
+		case isDigit(c) && j-i == 4:
+			add(sampleYear, s[i:j])
+
+		case isDigit(c) && j-i <= 2:
+			add(sampleNum, s[i:j])
+
+		case isDigit(c):
+			return nil, false
+
+		case (c == '.' || c == ',') && (last() == sampleSecond || last() == sampleCompactTime) && j < len(s) && isDigit(s[j]):
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+
+			add(sampleFrac, s[i:j])
+
+		case (c == '+' || c == '-') && hasTime && matchNum(s[j:], true) == 2:
+			switch {
+			case matchNumZone(s[i:], "07:00") == 6:
+				j = i + 6
+			case matchNumZone(s[i:], "0700") == 5:
+				j = i + 5
+			default:
+				j = i + 3
+			}
+
+			add(sampleZoneOffset, s[i:j])
+
+		case isLetter(c):
+			for j < len(s) && isLetter(s[j]) {
+				j++
+			}
+
+			w := s[i:j]
+			_, isZone := zoneAbbrevs[w]
+
+			switch {
+			case English.lookup(elemMonth, w) != -1 && len(w) == 3:
+				add(sampleMonth, w)
+			case English.lookup(elemLongMonth, w) != -1:
+				add(sampleLongMonth, w)
+			case English.lookup(elemWeekDay, w) != -1 && len(w) == 3:
+				add(sampleWeekDay, w)
+			case English.lookup(elemLongWeekDay, w) != -1:
+				add(sampleLongWeekDay, w)
+			case w == "AM" || w == "PM" || w == "am" || w == "pm":
+				add(samplePM, w)
+			case w == "Z" && hasTime:
+				add(sampleZoneOffset, w)
+			case isZone || w == "UTC" || w == "GMT":
+				add(sampleZoneAbbrev, w)
+			default:
+				add(sampleLiteral, w)
+			}
+
+		case c == ' ':
+			// A space in a layout matches any number of spaces
+			for j < len(s) && s[j] == ' ' {
+				j++
+			}
+
+			add(sampleLiteral, " ")
+
+		default:
+			add(sampleLiteral, s[i:j])
+		}
+
+		i = j
+	}
+
+	return items, true
+}
+
+// mergeItems adds the items of a sample to the fields of the previous samples,
+// and returns false if they don't have the same shape.
+func mergeItems(fields []inferField, items []inferItem) ([]inferField, bool) {
+	if fields == nil {
+		fields = make([]inferField, len(items))
+		for i, it := range items {
+			fields[i].kind = it.kind
+		}
+	}
+
+	// The time package accepts fractional seconds even if the layout doesn't have
+	// them, so samples with and without them are the same shape
+	if len(items) != len(fields) {
+		items = withoutFrac(items)
+		fields = withoutFracFields(fields)
+	}
+
+	if len(items) != len(fields) {
+		return nil, false
+	}
+
+	for i, it := range items {
+		f := &fields[i]
+
+		switch {
+		case f.kind == it.kind:
+		case f.kind == sampleMonth && it.kind == sampleLongMonth, f.kind == sampleLongMonth && it.kind == sampleMonth:
+			// May is both
+			f.kind = sampleLongMonth
+		default:
+			return nil, false
+		}
+
+		if it.kind == sampleLiteral && len(f.texts) > 0 && f.texts[0] != it.text {
+			return nil, false
+		}
+
+		f.texts = append(f.texts, it.text)
+
+		if it.kind == sampleNum {
+			var v int
+			for k := 0; k < len(it.text); k++ {
+				v = v*10 + int(it.text[k]-'0')
+			}
+
+			if v > f.max {
+				f.max = v
+			}
+
+			if len(it.text) == 1 {
+				f.short = true
+			}
+		}
+	}
+
+	return fields, true
+}
+
+func withoutFrac(items []inferItem) []inferItem {
+	var res []inferItem
+	for _, it := range items {
+		if it.kind != sampleFrac {
+			res = append(res, it)
+		}
+	}
+
+	return res
+}
+
+func withoutFracFields(fields []inferField) []inferField {
+	var res []inferField
+	for _, f := range fields {
+		if f.kind != sampleFrac {
+			res = append(res, f)
+		}
+	}
+
+	return res
+}
+
+// inferLayout builds the layout from the fields of all the samples.
+func inferLayout(fields []inferField) (Inference, error) {
+	inf := Inference{Confidence: 1}
+
+	values, err := inferDate(fields, &inf)
+	if err != nil {
+		return Inference{}, err
+	}
+
+	pm := false
+	for _, f := range fields {
+		pm = pm || f.kind == samplePM
+	}
+
+	var layout strings.Builder
+
+	for i, f := range fields {
+		switch f.kind {
+		case sampleLiteral:
+			layout.WriteString(f.texts[0])
+
+		case sampleNum:
+			layout.WriteString(values[i])
+
+		case sampleYear:
+			layout.WriteString("2006")
+
+		case sampleCompactDate:
+			layout.WriteString("20060102")
+
+		case sampleCompactTime:
+			layout.WriteString("150405")
+
+		case sampleHour:
+			switch {
+			case !pm:
+				layout.WriteString("15")
+			case allLen(f.texts, 2):
+				layout.WriteString("03")
+			default:
+				layout.WriteString("3")
+			}
+
+		case sampleMinute:
+			layout.WriteString("04")
+
+		case sampleSecond:
+			layout.WriteString("05")
+
+		case sampleFrac:
+			sep, n := f.texts[0][:1], len(f.texts[0])-1
+			for _, t := range f.texts {
+				if t[:1] != sep {
+					return Inference{}, fmt.Errorf("xtime/Infer: Samples have different fractional second separators")
+				}
+			}
+
+			if allLen(f.texts, n+1) {
+				layout.WriteString(sep + strings.Repeat("0", n))
+			} else {
+				layout.WriteString(sep + "999999999")
+			}
+
+		case sampleMonth:
+			layout.WriteString("Jan")
+
+		case sampleLongMonth:
+			layout.WriteString("January")
+
+		case sampleWeekDay:
+			layout.WriteString("Mon")
+
+		case sampleLongWeekDay:
+			layout.WriteString("Monday")
+
+		case samplePM:
+			upper, lower := true, true
+			for _, t := range f.texts {
+				upper, lower = upper && t[1] == 'M', lower && t[1] == 'm'
+			}
+
+			switch {
+			case upper:
+				layout.WriteString("PM")
+			case lower:
+				layout.WriteString("pm")
+			default:
+				return Inference{}, fmt.Errorf("xtime/Infer: Samples mix AM/PM and am/pm")
+			}
+
+		case sampleZoneAbbrev:
+			layout.WriteString("MST")
+
+		case sampleZoneOffset:
+			z, err := inferZone(f.texts)
+			if err != nil {
+				return Inference{}, err
+			}
+
+			layout.WriteString(z)
+		}
+	}
+
+	inf.Layout = layout.String()
+
+	return inf, nil
+}
+
+// inferDate returns the layout of each of the numeric date fields, by index, and
+// sets the date order and confidence of the inference.
+func inferDate(fields []inferField, inf *Inference) (map[int]string, error) {
+	var (
+		nums     []int
+		hasMonth bool
+		yearPos  = -1
+	)
+
+	for i, f := range fields {
+		switch f.kind {
+		case sampleNum:
+			nums = append(nums, i)
+		case sampleMonth, sampleLongMonth:
+			hasMonth = true
+		case sampleYear, sampleCompactDate:
+			yearPos = i
+		}
+	}
+
+	var (
+		values = make(map[int]string)
+		day    = func(i int) {
+			values[i] = "02"
+			if fields[i].short {
+				values[i] = "2"
+			}
+		}
+		month = func(i int) {
+			values[i] = "01"
+			if fields[i].short {
+				values[i] = "1"
+			}
+		}
+		year = func(i int) bool {
+			values[i] = "06"
+			return !fields[i].short
+		}
+	)
+
+	switch {
+	case len(nums) == 0:
+		return values, nil
+
+	case hasMonth && len(nums) == 1:
+		day(nums[0])
+		return values, nil
+
+	case hasMonth && len(nums) == 2 && yearPos == -1:
+		// A day and a 2 digit year, the day first unless its values say otherwise
+		d, y := nums[0], nums[1]
+		switch {
+		case fields[d].max > 31:
+			d, y = y, d
+		case fields[y].max <= 31:
+			inf.Confidence *= 0.5
+		}
+
+		day(d)
+		if !year(y) {
+			break
+		}
+
+		return values, nil
+
+	case !hasMonth && (len(nums) == 2 || (len(nums) == 3 && yearPos == -1)):
+		if len(nums) == 3 {
+			// A 2 digit year, at either end
+			y := nums[2]
+			switch {
+			case fields[nums[0]].max > 31:
+				y, nums = nums[0], nums[1:]
+				yearPos = y
+			case fields[nums[2]].max > 31:
+				nums = nums[:2]
+			default:
+				inf.Confidence *= 0.5
+				nums = nums[:2]
+			}
+
+			if !year(y) {
+				break
+			}
+
+			if yearPos == -1 {
+				yearPos = y
+			}
+		}
+
+		var (
+			m, d      = nums[0], nums[1]
+			yearFirst = yearPos != -1 && yearPos < m
+
+			// Dates such as 13.02.2024 are day first
+			dayFirst = !yearFirst && fields[m+1].kind == sampleLiteral && fields[m+1].texts[0] == "."
+		)
+
+		switch {
+		case fields[m].max > 12 && fields[d].max > 12:
+			return nil, fmt.Errorf("xtime/Infer: No month in the numeric date")
+		case fields[m].max > 12:
+			dayFirst = true
+		case fields[d].max > 12:
+			dayFirst = false
+		case yearFirst:
+			inf.Confidence *= 0.9
+		default:
+			inf.Confidence *= 0.5
+		}
+
+		if dayFirst {
+			m, d = d, m
+		}
+
+		month(m)
+		day(d)
+
+		switch {
+		case yearFirst && !dayFirst:
+			inf.Order = YMD
+		case !yearFirst && dayFirst:
+			inf.Order = DMY
+		case !yearFirst:
+			inf.Order = MDY
+		}
+
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("xtime/Infer: Unknown date format")
+}
+
+// inferZone returns the layout of the numeric zone offsets.
+func inferZone(texts []string) (string, error) {
+	var (
+		shape string
+		z     bool
+	)
+
+	for _, t := range texts {
+		if t == "Z" {
+			z = true
+			continue
+		}
+
+		s := map[int]string{3: "07", 5: "0700", 6: "07:00"}[len(t)]
+		if shape != "" && s != shape {
+			return "", fmt.Errorf("xtime/Infer: Samples have different zone offsets")
+		}
+
+		shape = s
+	}
+
+	switch {
+	case shape == "":
+		return "Z07:00", nil
+	case z:
+		return "Z" + shape, nil
+	}
+
+	return "-" + shape, nil
+}
+
+func allLen(texts []string, n int) bool {
+	for _, t := range texts {
+		if len(t) != n {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
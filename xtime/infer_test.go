@@ -0,0 +1,73 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfer(t *testing.T) {
+	for _, c := range []struct {
+		samples    []string
+		layout     string
+		order      DateOrder
+		confidence float64
+	}{
+		{[]string{"13/02/2024 10:00:00.123", "03/02/2024 09:15:00.456"}, "02/01/2006 15:04:05.000", DMY, 1},
+		{[]string{"02/13/2024 10:00:00", "2/3/2024 9:15:00"}, "1/2/2006 15:04:05", MDY, 1},
+		{[]string{"03/02/2024 10:00:00"}, "01/02/2006 15:04:05", MDY, 0.5},
+		{[]string{"03.02.2024 10:00"}, "02.01.2006 15:04", DMY, 0.5},
+		{[]string{"2024-02-03 10:00:00,123"}, "2006-01-02 15:04:05,000", YMD, 0.9},
+		{[]string{"2024-02-13T10:00:00Z", "2024-02-13T10:00:00.5+01:00"}, "2006-01-02T15:04:05Z07:00", YMD, 1},
+		{[]string{"2024-02-13T10:00:00.123456+0100", "2024-02-13T10:00:00.12+0100"}, "2006-01-02T15:04:05.999999999-0700", YMD, 1},
+		{[]string{"Feb 13 10:00:00", "Feb  3 10:00:00"}, "Jan 2 15:04:05", DateOrderAuto, 1},
+		{[]string{"Tue, 13 Feb 2024 10:00:00 PST"}, "Mon, 02 Jan 2006 15:04:05 MST", DateOrderAuto, 1},
+		{[]string{"13-May-24 3:04:05 pm", "13-September-24 11:04:05 am"}, "02-January-06 3:04:05 pm", DateOrderAuto, 0.5},
+		{[]string{"20240213T100000Z"}, "20060102T150405Z07:00", DateOrderAuto, 1},
+		{[]string{"98/12/31 10:00:00", "24/02/13 10:00:00"}, "06/01/02 15:04:05", YMD, 1},
+	} {
+		inf, err := Infer(c.samples)
+		require.NoError(t, err, c.samples[0])
+		require.Equal(t, c.layout, inf.Layout, c.samples[0])
+		require.Equal(t, c.order, inf.Order, c.samples[0])
+		require.Equal(t, c.confidence, inf.Confidence, c.samples[0])
+	}
+
+	for _, samples := range [][]string{
+		nil,
+		{"not a time"},
+		{"13/13/2024 10:00:00"},
+		{"2024-02-13 10:00:00", "13/02/2024 10:00:00"},
+		{"1696000000"},
+	} {
+		_, err := Infer(samples)
+		require.Error(t, err, samples)
+	}
+}
+
+func TestLearn(t *testing.T) {
+	p := NewParser()
+
+	inf, err := p.Learn([]string{"13.02.2024 10:00:00"})
+	require.NoError(t, err)
+	require.Equal(t, []string{inf.Layout}, p.Formats())
+
+	tm, err := p.Parse("03.02.2024 10:00:00")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 3, 10, 0, 0, 0, time.UTC), tm)
+}
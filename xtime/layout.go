@@ -0,0 +1,541 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import "strings"
+
+type elemKind int
+
+// The layout elements, named after the reference time values in the time package.
+const (
+	elemLiteral      elemKind = iota
+	elemLongMonth             // January
+	elemMonth                 // Jan
+	elemNumMonth              // 1
+	elemZeroMonth             // 01
+	elemLongWeekDay           // Monday
+	elemWeekDay               // Mon
+	elemDay                   // 2
+	elemUnderDay              // _2
+	elemZeroDay               // 02
+	elemUnderYearDay          // __2
+	elemZeroYearDay           // 002
+	elemHour                  // 15
+	elemHour12                // 3
+	elemZeroHour12            // 03
+	elemMinute                // 4
+	elemZeroMinute            // 04
+	elemSecond                // 5
+	elemZeroSecond            // 05
+	elemLongYear              // 2006
+	elemYear                  // 06
+	elemPM                    // PM
+	elemLowerPM               // pm
+	elemTZ                    // MST
+	elemISO8601TZ             // Z0700, Z07:00, Z07, Z070000, Z07:00:00
+	elemNumTZ                 // -0700, -07:00, -07, -070000, -07:00:00
+	elemFracSecond0           // .000 or ,000
+	elemFracSecond9           // .999 or ,999
+)
+
+// layoutElem is one element of a time layout, either a literal or one of the
+// reference time values.
+type layoutElem struct {
+	kind  elemKind
+	value string // The layout text of the element
+}
+
+// parseLayout splits a layout, in the format of the time package, into its
+// elements. It follows the same rules as the time package, so the elements are
+// exactly the ones time.Parse uses.
+func parseLayout(layout string) []layoutElem {
+	var elems []layoutElem
+
+	for layout != "" {
+		prefix, kind, value, suffix := nextElem(layout)
+
+		if prefix != "" {
+			elems = append(elems, layoutElem{kind: elemLiteral, value: prefix})
+		}
+
+		if value != "" {
+			elems = append(elems, layoutElem{kind: kind, value: value})
+		}
+
+		layout = suffix
+	}
+
+	return elems
+}
+
+// nextElem finds the first reference time value in the layout, and returns the
+// literal text before it, the value, and the rest of the layout.
+func nextElem(layout string) (prefix string, kind elemKind, value string, suffix string) {
+	elem := func(i, n int, k elemKind) (string, elemKind, string, string) {
+		return layout[:i], k, layout[i : i+n], layout[i+n:]
+	}
+
+	for i := 0; i < len(layout); i++ {
+		rest := layout[i:]
+
+		switch layout[i] {
+		case 'J': // January, Jan
+			if hasPrefix(rest, "January") {
+				return elem(i, 7, elemLongMonth)
+			}
+
+			if hasPrefix(rest, "Jan") && !startsWithLower(rest[3:]) {
+				return elem(i, 3, elemMonth)
+			}
+
+		case 'M': // Monday, Mon, MST
+			if hasPrefix(rest, "Monday") {
+				return elem(i, 6, elemLongWeekDay)
+			}
+
+			if hasPrefix(rest, "Mon") && !startsWithLower(rest[3:]) {
+				return elem(i, 3, elemWeekDay)
+			}
+
+			if hasPrefix(rest, "MST") {
+				return elem(i, 3, elemTZ)
+			}
+
+		case '0': // 01, 02, 03, 04, 05, 06, 002
+			if len(rest) >= 2 && '1' <= rest[1] && rest[1] <= '6' {
+				return elem(i, 2, [...]elemKind{elemZeroMonth, elemZeroDay, elemZeroHour12, elemZeroMinute, elemZeroSecond, elemYear}[rest[1]-'1'])
+			}
+
+			if hasPrefix(rest, "002") {
+				return elem(i, 3, elemZeroYearDay)
+			}
+
+		case '1': // 15, 1
+			if hasPrefix(rest, "15") {
+				return elem(i, 2, elemHour)
+			}
+
+			return elem(i, 1, elemNumMonth)
+
+		case '2': // 2006, 2
+			if hasPrefix(rest, "2006") {
+				return elem(i, 4, elemLongYear)
+			}
+
+			return elem(i, 1, elemDay)
+
+		case '_': // _2, _2006, __2
+			if hasPrefix(rest, "_2006") {
+				// A literal _ followed by the year
+				return elem(i+1, 4, elemLongYear)
+			}
+
+			if hasPrefix(rest, "_2") {
+				return elem(i, 2, elemUnderDay)
+			}
+
+			if hasPrefix(rest, "__2") {
+				return elem(i, 3, elemUnderYearDay)
+			}
+
+		case '3':
+			return elem(i, 1, elemHour12)
+
+		case '4':
+			return elem(i, 1, elemMinute)
+
+		case '5':
+			return elem(i, 1, elemSecond)
+
+		case 'P': // PM
+			if hasPrefix(rest, "PM") {
+				return elem(i, 2, elemPM)
+			}
+
+		case 'p': // pm
+			if hasPrefix(rest, "pm") {
+				return elem(i, 2, elemLowerPM)
+			}
+
+		case '-', 'Z': // -070000, -07:00:00, -0700, -07:00, -07, and the same with Z
+			kind := elemNumTZ
+			if layout[i] == 'Z' {
+				kind = elemISO8601TZ
+			}
+
+			for _, z := range []string{"070000", "07:00:00", "0700", "07:00", "07"} {
+				if hasPrefix(rest[1:], z) {
+					return elem(i, len(z)+1, kind)
+				}
+			}
+
+		case '.', ',': // .000, ,000, .999, ,999
+			if len(rest) > 1 && (rest[1] == '0' || rest[1] == '9') {
+				j := 1
+				for j < len(rest) && rest[j] == rest[1] {
+					j++
+				}
+
+				// Only a fractional second if not followed by more digits
+				if j == len(rest) || !isDigit(rest[j]) {
+					if rest[1] == '0' {
+						return elem(i, j, elemFracSecond0)
+					}
+
+					return elem(i, j, elemFracSecond9)
+				}
+			}
+		}
+	}
+
+	return layout, elemLiteral, "", ""
+}
+
+// layoutHas returns true if any of the elements is of one of the kinds.
+func layoutHas(elems []layoutElem, kinds ...elemKind) bool {
+	for _, e := range elems {
+		for _, k := range kinds {
+			if e.kind == k {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func hasPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
+}
+
+func startsWithLower(s string) bool {
+	return len(s) > 0 && 'a' <= s[0] && s[0] <= 'z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// maxElemLens is the most lengths an element can match at one position.
+const maxElemLens = 8
+
+// match stores in lens the lengths of the start of s that the element matches,
+// longest first, and returns how many there are. It accepts everything that
+// time.Parse accepts for the element, so a format is never missed; time.Parse still
+// has the final say on the values.
+func (e layoutElem) match(s string, o *options, lens *[maxElemLens]int) int {
+	n := -1
+
+	switch e.kind {
+	case elemLiteral:
+		n = matchLiteral(s, e.value)
+
+	case elemNumMonth, elemDay, elemHour, elemHour12, elemMinute:
+		n = matchNum(s, false)
+
+	case elemZeroMonth, elemZeroDay, elemZeroHour12, elemZeroMinute:
+		n = matchNum(s, true)
+
+	case elemSecond, elemZeroSecond:
+		if n = matchNum(s, e.kind == elemZeroSecond); n == -1 {
+			return 0
+		}
+
+		// The time package accepts fractional seconds even if they're not in
+		// the format, unless the format has them next.
+		if f := matchFraction(s[n:], false); f > 0 {
+			lens[0], lens[1] = n+f, n
+			return 2
+		}
+
+	case elemUnderDay:
+		if len(s) > 0 && s[0] == ' ' {
+			if n = matchNum(s[1:], false); n != -1 {
+				n++
+			}
+		} else {
+			n = matchNum(s, false)
+		}
+
+	case elemUnderYearDay, elemZeroYearDay:
+		i := 0
+		for e.kind == elemUnderYearDay && i < 2 && i < len(s) && s[i] == ' ' {
+			i++
+		}
+
+		d := 0
+		for d < 3 && i+d < len(s) && isDigit(s[i+d]) {
+			d++
+		}
+
+		if d > 0 && (e.kind == elemUnderYearDay || d == 3) {
+			n = i + d
+		}
+
+	case elemLongYear:
+		if len(s) >= 4 && (isDigit(s[0]) || s[0] == '+' || s[0] == '-') && allDigits(s[1:4]) {
+			n = 4
+		}
+
+	case elemYear:
+		if len(s) >= 2 && (isDigit(s[0]) || s[0] == '+' || s[0] == '-') && isDigit(s[1]) {
+			n = 2
+		}
+
+	case elemLongMonth, elemMonth, elemLongWeekDay, elemWeekDay:
+		k := matchNames(s, lens, 0, English.names(e.kind))
+		if o.locale != nil && o.locale != English {
+			k = matchNames(s, lens, k, o.locale.names(e.kind))
+		}
+
+		return k
+
+	case elemPM, elemLowerPM:
+		if len(s) >= 2 && (s[0]|0x20 == 'a' || s[0]|0x20 == 'p') && s[1]|0x20 == 'm' {
+			n = 2
+		}
+
+	case elemTZ:
+		if hasPrefix(s, "UTC") {
+			n = 3
+		} else {
+			n = matchZoneName(s)
+		}
+
+	case elemISO8601TZ:
+		if len(s) > 0 && s[0] == 'Z' {
+			n = 1
+		} else {
+			n = matchNumZone(s, e.value[1:])
+		}
+
+	case elemNumTZ:
+		n = matchNumZone(s, e.value[1:])
+
+	case elemFracSecond0:
+		if len(s) >= len(e.value) && (s[0] == '.' || s[0] == ',') && allDigits(s[1:len(e.value)]) {
+			n = len(e.value)
+		}
+
+	case elemFracSecond9:
+		n = matchFraction(s, true)
+	}
+
+	if n == -1 {
+		return 0
+	}
+
+	lens[0] = n
+
+	return 1
+}
+
+// matchLiteral matches the literal text the same way time.Parse does, where a space
+// matches any number of spaces.
+func matchLiteral(s, lit string) int {
+	n := 0
+
+	for len(lit) > 0 {
+		if lit[0] == ' ' {
+			if n < len(s) && s[n] != ' ' {
+				return -1
+			}
+
+			for len(lit) > 0 && lit[0] == ' ' {
+				lit = lit[1:]
+			}
+
+			for n < len(s) && s[n] == ' ' {
+				n++
+			}
+
+			continue
+		}
+
+		if n >= len(s) || s[n] != lit[0] {
+			return -1
+		}
+
+		n++
+		lit = lit[1:]
+	}
+
+	return n
+}
+
+// matchNum matches a 1 or 2 digit number, or exactly 2 digits if fixed.
+func matchNum(s string, fixed bool) int {
+	switch {
+	case len(s) == 0 || !isDigit(s[0]):
+		return -1
+	case len(s) > 1 && isDigit(s[1]):
+		return 2
+	case fixed:
+		return -1
+	}
+
+	return 1
+}
+
+// matchFraction matches a period or comma followed by digits. If optional, no
+// fraction at all is a match of length 0.
+func matchFraction(s string, optional bool) int {
+	if len(s) < 2 || (s[0] != '.' && s[0] != ',') || !isDigit(s[1]) {
+		if optional {
+			return 0
+		}
+
+		return -1
+	}
+
+	n := 2
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+
+	return n
+}
+
+// matchNames adds to the k lengths in lens the lengths of all the names that s
+// starts with, ignoring case, and returns the new number of lengths. Names ending
+// with a period also match without it. The lengths are kept longest first.
+func matchNames(s string, lens *[maxElemLens]int, k int, names []string) int {
+	add := func(n int) {
+		for i := 0; i < k; i++ {
+			if lens[i] == n {
+				return
+			}
+		}
+
+		if k == len(lens) {
+			return
+		}
+
+		i := k
+		for ; i > 0 && lens[i-1] < n; i-- {
+			lens[i] = lens[i-1]
+		}
+
+		lens[i] = n
+		k++
+	}
+
+	for _, name := range names {
+		if len(s) >= len(name) && strings.EqualFold(s[:len(name)], name) {
+			add(len(name))
+		} else if n := len(name) - 1; name[n] == '.' && len(s) >= n && strings.EqualFold(s[:n], name[:n]) {
+			add(n)
+		}
+	}
+
+	return k
+}
+
+// matchNumZone matches a numeric zone offset with the given shape, one of 0700,
+// 07:00, 07, 070000 or 07:00:00, after the sign.
+func matchNumZone(s, shape string) int {
+	if len(s) < len(shape)+1 || (s[0] != '+' && s[0] != '-') {
+		return -1
+	}
+
+	for i := 0; i < len(shape); i++ {
+		if c := s[i+1]; (shape[i] == ':' && c != ':') || (shape[i] != ':' && !isDigit(c)) {
+			return -1
+		}
+	}
+
+	return len(shape) + 1
+}
+
+// matchZoneName matches a zone abbreviation the same way time.Parse does: 3 upper
+// case letters, 4 or 5 ending in T, a few special cases, and GMT or a sign followed
+// by an hour offset.
+func matchZoneName(s string) int {
+	if len(s) < 3 {
+		return -1
+	}
+
+	if hasPrefix(s, "ChST") || hasPrefix(s, "MeST") {
+		return 4
+	}
+
+	if hasPrefix(s, "GMT") {
+		if n := matchSignedHours(s[3:]); n > 0 {
+			return 3 + n
+		}
+
+		return 3
+	}
+
+	if s[0] == '+' || s[0] == '-' {
+		if n := matchSignedHours(s); n > 0 {
+			return n
+		}
+
+		return -1
+	}
+
+	upper := 0
+	for upper < 6 && upper < len(s) && 'A' <= s[upper] && s[upper] <= 'Z' {
+		upper++
+	}
+
+	switch upper {
+	case 3:
+		return 3
+	case 4:
+		if s[3] == 'T' || hasPrefix(s, "WITA") {
+			return 4
+		}
+	case 5:
+		if s[4] == 'T' {
+			return 5
+		}
+	}
+
+	return -1
+}
+
+// matchSignedHours matches a sign followed by an hour offset from 0 to 23.
+func matchSignedHours(s string) int {
+	if len(s) < 2 || (s[0] != '+' && s[0] != '-') {
+		return 0
+	}
+
+	n, v := 1, 0
+	for n < len(s) && isDigit(s[n]) {
+		if v = v*10 + int(s[n]-'0'); v > 23 {
+			return 0
+		}
+
+		n++
+	}
+
+	if n == 1 {
+		return 0
+	}
+
+	return n
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
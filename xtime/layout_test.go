@@ -0,0 +1,99 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayout(t *testing.T) {
+	require.Equal(t, []layoutElem{
+		{elemWeekDay, "Mon"},
+		{elemLiteral, ", "},
+		{elemZeroDay, "02"},
+		{elemLiteral, " "},
+		{elemMonth, "Jan"},
+		{elemLiteral, " "},
+		{elemLongYear, "2006"},
+		{elemLiteral, " "},
+		{elemHour, "15"},
+		{elemLiteral, ":"},
+		{elemZeroMinute, "04"},
+		{elemLiteral, ":"},
+		{elemZeroSecond, "05"},
+		{elemFracSecond0, ",000"},
+		{elemLiteral, " "},
+		{elemTZ, "MST"},
+	}, parseLayout("Mon, 02 Jan 2006 15:04:05,000 MST"))
+
+	require.Equal(t, []layoutElem{
+		{elemLongYear, "2006"},
+		{elemLiteral, "-"},
+		{elemZeroMonth, "01"},
+		{elemLiteral, "-"},
+		{elemZeroDay, "02"},
+		{elemLiteral, "T"},
+		{elemHour, "15"},
+		{elemLiteral, ":"},
+		{elemZeroMinute, "04"},
+		{elemLiteral, ":"},
+		{elemZeroSecond, "05"},
+		{elemFracSecond9, ".999999999"},
+		{elemISO8601TZ, "Z07:00"},
+	}, parseLayout("2006-01-02T15:04:05.999999999Z07:00"))
+
+	require.Equal(t, []layoutElem{
+		{elemUnderDay, "_2"},
+		{elemLiteral, "/"},
+		{elemMonth, "Jan"},
+		{elemLiteral, "/"},
+		{elemLongYear, "2006"},
+		{elemLiteral, ":"},
+		{elemHour, "15"},
+		{elemLiteral, ":"},
+		{elemZeroMinute, "04"},
+		{elemLiteral, ":"},
+		{elemZeroSecond, "05"},
+		{elemLiteral, " "},
+		{elemNumTZ, "-0700"},
+	}, parseLayout("_2/Jan/2006:15:04:05 -0700"))
+
+	require.Equal(t, []layoutElem{
+		{elemNumMonth, "1"},
+		{elemLiteral, "/"},
+		{elemDay, "2"},
+		{elemLiteral, "/"},
+		{elemYear, "06"},
+		{elemLiteral, " "},
+		{elemHour12, "3"},
+		{elemLiteral, ":"},
+		{elemZeroMinute, "04"},
+		{elemLiteral, " "},
+		{elemPM, "PM"},
+		{elemLiteral, " "},
+		{elemLongWeekDay, "Monday"},
+		{elemLiteral, " "},
+		{elemLongMonth, "January"},
+		{elemLiteral, " "},
+		{elemZeroYearDay, "002"},
+		{elemLiteral, "_"},
+		{elemLongYear, "2006"},
+	}, parseLayout("1/2/06 3:04 PM Monday January 002_2006"))
+
+	// Not reference values
+	require.Equal(t, []layoutElem{{elemLiteral, "Janet Monty"}}, parseLayout("Janet Monty"))
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import "strings"
+
+// Locale is a set of month and weekday names, used in place of the English names in
+// the formats, such as "févr." for "Jan" or "Montag" for "Monday". Names are matched
+// ignoring case, and abbreviations ending with a period also match without it.
+type Locale struct {
+	Name        string
+	Months      [12]string
+	ShortMonths [12]string
+	Days        [7]string // Starting with Sunday
+	ShortDays   [7]string
+}
+
+var (
+	// English is the locale of the time package, always accepted.
+	English = &Locale{
+		Name: "en",
+		Months: [12]string{"January", "February", "March", "April", "May", "June", "July", "August",
+			"September", "October", "November", "December"},
+		ShortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		Days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		ShortDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	}
+
+	French = &Locale{
+		Name: "fr",
+		Months: [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août",
+			"septembre", "octobre", "novembre", "décembre"},
+		ShortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.",
+			"nov.", "déc."},
+		Days:      [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		ShortDays: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	}
+
+	German = &Locale{
+		Name: "de",
+		Months: [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September",
+			"Oktober", "November", "Dezember"},
+		ShortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.",
+			"Nov.", "Dez."},
+		Days:      [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		ShortDays: [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+	}
+
+	Spanish = &Locale{
+		Name: "es",
+		Months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto",
+			"septiembre", "octubre", "noviembre", "diciembre"},
+		ShortMonths: [12]string{"ene.", "feb.", "mar.", "abr.", "may.", "jun.", "jul.", "ago.", "sept.", "oct.",
+			"nov.", "dic."},
+		Days:      [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		ShortDays: [7]string{"dom.", "lun.", "mar.", "mié.", "jue.", "vie.", "sáb."},
+	}
+
+	// Japanese is for names such as "2月" in the English formats, dates such as
+	// "2024年2月12日" are in TimeFormats.
+	Japanese = &Locale{
+		Name:        "ja",
+		Months:      [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		ShortMonths: [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		Days:        [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		ShortDays:   [7]string{"日", "月", "火", "水", "木", "金", "土"},
+	}
+)
+
+// names returns the names for the element kind, nil if it's not a name.
+func (l *Locale) names(kind elemKind) []string {
+	if l == nil {
+		return nil
+	}
+
+	switch kind {
+	case elemLongMonth:
+		return l.Months[:]
+	case elemMonth:
+		return l.ShortMonths[:]
+	case elemLongWeekDay:
+		return l.Days[:]
+	case elemWeekDay:
+		return l.ShortDays[:]
+	}
+
+	return nil
+}
+
+// lookup returns the index of the name that is exactly s, or -1 if none.
+func (l *Locale) lookup(kind elemKind, s string) int {
+	for i, name := range l.names(kind) {
+		if strings.EqualFold(s, name) || (strings.HasSuffix(name, ".") && strings.EqualFold(s, name[:len(name)-1])) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// translate rewrites the localized month and weekday names in the time string into
+// the English names of the time package, so it can be parsed with the layout
+// elements. It returns t as is if it doesn't match the elements.
+func translate(elems []layoutElem, t string, o *options) string {
+	if o.locale == nil || o.locale == English || !layoutHas(elems, elemLongMonth, elemMonth, elemLongWeekDay, elemWeekDay) {
+		return t
+	}
+
+	ends := make([]int, len(elems))
+	if !matchElems(elems, t, o, ends, 0) {
+		return t
+	}
+
+	var (
+		buf   strings.Builder
+		start int
+	)
+
+	for i, e := range elems {
+		name := t[start:ends[i]]
+
+		if n := o.locale.lookup(e.kind, name); n != -1 && English.lookup(e.kind, name) == -1 {
+			name = English.names(e.kind)[n]
+		}
+
+		buf.WriteString(name)
+		start = ends[i]
+	}
+
+	return buf.String()
+}
+
+// matchElems matches all of t from pos with the elements, backtracking like the
+// format tree, and stores where each element ends.
+func matchElems(elems []layoutElem, t string, o *options, ends []int, pos int) bool {
+	if len(elems) == 0 {
+		return pos == len(t)
+	}
+
+	var lens [maxElemLens]int
+
+	k := elems[0].match(t[pos:], o, &lens)
+	for _, l := range lens[:k] {
+		ends[0] = pos + l
+
+		if matchElems(elems[1:], t, o, ends[1:], pos+l) {
+			return true
+		}
+	}
+
+	return false
+}
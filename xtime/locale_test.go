@@ -0,0 +1,102 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocale(t *testing.T) {
+	p := NewParser(TimeFormats...)
+	require.NoError(t, p.Add("02 Jan 2006 15:04:05"))
+	require.NoError(t, p.Add("Monday 2 January 2006"))
+
+	exp := time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC)
+
+	for _, c := range []struct {
+		l  *Locale
+		in string
+	}{
+		{French, "12 févr. 2024 10:00:00"},
+		{French, "12 FÉVR 2024 10:00:00"},
+		{French, "lun., 12 févr. 2024 10:00:00 +0000"},
+		{German, "Mo, 12 Feb 2024 10:00:00 +0000"},
+		{German, "Mo., 12 Feb. 2024 10:00:00 UTC"},
+		{Spanish, "lun, 12 feb 2024 10:00:00 +0000"},
+		{Japanese, "月, 12 2月 2024 10:00:00 +0000"},
+		{English, "Mon, 12 Feb 2024 10:00:00 +0000"},
+	} {
+		tm, err := p.ParseWith(c.in, WithLocale(c.l))
+		require.NoError(t, err, c.in)
+		require.Equal(t, exp.UnixNano(), tm.UnixNano(), c.in)
+	}
+
+	// English names are still accepted
+	tm, err := p.ParseWith("12 Feb 2024 10:00:00", WithLocale(German))
+	require.NoError(t, err)
+	require.Equal(t, exp, tm)
+
+	// Only with the locale
+	_, err = p.Parse("12 févr. 2024 10:00:00")
+	require.Error(t, err)
+
+	// The same abbreviation for March and Tuesday
+	tm, err = p.ParseWith("martes 12 marzo 2024", WithLocale(Spanish))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC), tm)
+
+	tm, err = p.ParseWith("mar., 12 mar. 2024 10:00:00 +0000", WithLocale(Spanish))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC).UnixNano(), tm.UnixNano())
+
+	// Japanese dates are numbers with 年, 月 and 日, and don't need the locale
+	for _, in := range []string{"2024年2月12日 10:00:00", "2024年02月12日 10:00:00"} {
+		tm, err = Parse(in)
+		require.NoError(t, err, in)
+		require.Equal(t, exp, tm, in)
+
+		tm, err = ParseWith(in, WithLocale(Japanese))
+		require.NoError(t, err, in)
+		require.Equal(t, exp, tm, in)
+	}
+
+	tm, err = Parse("2024年2月12日")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC), tm)
+
+	// For every parse of the parser
+	p.SetOptions(WithLocale(German))
+
+	tm, err = p.Parse("Dienstag 12 März 2024")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC), tm)
+}
+
+func TestMatchNames(t *testing.T) {
+	var lens [maxElemLens]int
+
+	k := matchNames("févr. 2024", &lens, 0, French.ShortMonths[:])
+	require.Equal(t, []int{6}, lens[:k])
+
+	k = matchNames("févr 2024", &lens, 0, French.ShortMonths[:])
+	require.Equal(t, []int{5}, lens[:k])
+
+	k = matchNames("Juni", &lens, 0, English.ShortMonths[:])
+	k = matchNames("Juni", &lens, k, German.ShortMonths[:])
+	require.Equal(t, []int{4, 3}, lens[:k])
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchSharedPrefix(t *testing.T) {
+	for _, c := range []struct {
+		in, layout string
+	}{
+		{"2015-02-06T15:45:16Z", "2006-01-02T15:04:05Z07:00"},
+		{"2015-02-06T15:45:16.123+08:00", "2006-01-02T15:04:05.999999999Z07:00"},
+		{"Jul 20 2020 08:00:00", "Jan 2 2006 15:04:05"},
+		{"Jul 20 08:00:00 2020", "Jan 2 15:04:05 2006"},
+		{"Dec 31 20:00:00 -0700", "Jan 2 15:04:05 -0700"},
+		{"Wednesday, 04-Feb-15 10:00:00 UTC", "Monday, 02-Jan-06 15:04:05 MST"},
+		{"12/25/2006 3:04:05 PM", "1/2/2006 3:04:05 PM"},
+		{"2015-02-06 15:45:16,123 -0700", "2006-01-02 15:04:05,000 -0700"},
+		{"2015-02-06 15:45:16 -0700", "2006-01-02 15:04:05 -0700"},
+		{"2015-02-06 15:45:16-0700", "2006-01-02 15:04:05-0700"},
+		{"2015-02-06 15:45:16,123", "2006-01-02 15:04:05,000"},
+	} {
+		res, err := Detect(c.in)
+		require.NoError(t, err, c.in)
+		require.Equal(t, c.layout, res.Layout, c.in)
+
+		exp, err := time.Parse(c.layout, c.in)
+		require.NoError(t, err)
+		require.Equal(t, exp.UnixNano(), res.Time.UnixNano(), c.in)
+	}
+}
+
+func TestMatchStrictBeforeLoose(t *testing.T) {
+	// The format without fractional seconds also accepts them, but the one that has
+	// them is a better match
+	res, err := Detect("Feb  6 15:45:16.123")
+	require.NoError(t, err)
+	require.Equal(t, "Jan _2 15:04:05.000", res.Layout)
+
+	res, err = Detect("Feb  6 15:45:16.123456789")
+	require.NoError(t, err)
+	require.Equal(t, "Jan _2 15:04:05.000000000", res.Layout)
+
+	// No format has 4 digits, so the loose match is used
+	res, err = Detect("Feb  6 15:45:16.1234")
+	require.NoError(t, err)
+	require.Equal(t, "Jan _2 15:04:05", res.Layout)
+	require.Equal(t, 123400000, res.Time.Nanosecond())
+}
+
+func TestMatchElem(t *testing.T) {
+	var (
+		o    = newOptions()
+		lens [maxElemLens]int
+	)
+
+	for _, c := range []struct {
+		layout, in string
+		exp        []int
+	}{
+		{"2", "25", []int{2}},
+		{"2", "5x", []int{1}},
+		{"02", "5x", nil},
+		{"_2", " 5", []int{2}},
+		{"2006", "2015", []int{4}},
+		{"2006", "201", nil},
+		{"Jan", "jul", []int{3}},
+		{"January", "Mayday", []int{3}},
+		{"PM", "am", []int{2}},
+		{"MST", "CEST", []int{4}},
+		{"MST", "GMT+8", []int{5}},
+		{"MST", "Cest", nil},
+		{"-0700", "+0800", []int{5}},
+		{"-07:00", "+0800", nil},
+		{"Z07:00", "Z", []int{1}},
+		{".000", ",123", []int{4}},
+		{".000", ".12", nil},
+		{".999", ".12345", []int{6}},
+		{".999", "x", []int{0}},
+		{"05", "16.123", []int{6, 2}},
+		{"05", "16.", []int{2}},
+	} {
+		elems := parseLayout(c.layout)
+		require.Len(t, elems, 1, c.layout)
+
+		k := elems[0].match(c.in, &o, &lens)
+		require.Equal(t, len(c.exp), k, c.layout+" "+c.in)
+
+		for i, l := range c.exp {
+			require.Equal(t, l, lens[i], c.layout+" "+c.in)
+		}
+	}
+
+	// A space matches any number of spaces
+	require.Equal(t, 4, matchLiteral("   x", " x"))
+	require.Equal(t, -1, matchLiteral("x", " x"))
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import "time"
+
+// Option changes how ParseWith parses a time string.
+type Option func(*options)
+
+type options struct {
+	loc   *time.Location
+	ref   time.Time
+	order DateOrder
+
+	locale *Locale
+
+	zones       map[string]int // Offsets of zone abbreviations, in seconds east of UTC
+	strictZones bool
+
+	epochUnit          EpochUnit
+	epochMin, epochMax time.Time
+}
+
+func newOptions(opts ...[]Option) options {
+	o := options{
+		loc:      time.UTC,
+		epochMin: defaultEpochMin,
+		epochMax: defaultEpochMax,
+	}
+
+	n := 0
+	for _, l := range opts {
+		n += len(l)
+	}
+
+	if n == 0 {
+		// Without options, o doesn't escape to the heap
+		return o
+	}
+
+	po := new(options)
+	*po = o
+
+	for _, l := range opts {
+		for _, opt := range l {
+			opt(po)
+		}
+	}
+
+	return *po
+}
+
+// WithLocation sets the location of the times that don't have a zone or offset, such
+// as "2006-01-02 15:04:05". The default is UTC. Times with an explicit offset keep
+// their offset, the same as time.ParseInLocation. Times without a year keep year 0
+// but get the offset the location has in the year of the reference time.
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		if loc == nil {
+			loc = time.UTC
+		}
+
+		o.loc = loc
+	}
+}
+
+// WithReference sets the reference time used to infer the year of the times that
+// don't have one, such as the syslog "Jan _2 15:04:05". The year picked is the one
+// that puts the time closest to the reference time, without being more than a week
+// in its future, so "Dec 31 23:59:59" read on Jan 1 is in the previous year. Without
+// a reference time, such times are returned in year 0.
+func WithReference(ref time.Time) Option {
+	return func(o *options) {
+		o.ref = ref
+	}
+}
+
+// WithEpochUnit forces the unit of numeric epoch timestamps, such as 1696000000,
+// instead of inferring it from the magnitude of the number.
+func WithEpochUnit(unit EpochUnit) Option {
+	return func(o *options) {
+		o.epochUnit = unit
+	}
+}
+
+// WithEpochRange sets the range of plausible epoch timestamps. Numbers that don't
+// fall in [min, max) are not accepted as epoch timestamps. The default range is
+// from 1980 to 2100.
+func WithEpochRange(min, max time.Time) Option {
+	return func(o *options) {
+		o.epochMin, o.epochMax = min, max
+	}
+}
+
+// WithDateOrder sets the preferred order of the day, month and year in numeric
+// dates, such as DMY to read 03/02/2024 as February 3. If the date isn't valid in
+// that order, such as 13/02/2024 read as MDY, or the format can't be read in that
+// order, another order is used. The default, DateOrderAuto, prefers the order of
+// the format that matched.
+func WithDateOrder(order DateOrder) Option {
+	return func(o *options) {
+		o.order = order
+	}
+}
+
+// WithLocale sets the locale of the month and weekday names, such as French to parse
+// "12 févr. 2024", in addition to the English names. Use Parser.SetOptions to set it
+// for every parse of a parser.
+func WithLocale(l *Locale) Option {
+	return func(o *options) {
+		o.locale = l
+	}
+}
+
+// WithZoneAbbrev sets the offset from UTC of a time zone abbreviation, such as
+// WithZoneAbbrev("IST", time.Hour) to read IST as Irish Standard Time instead of
+// India Standard Time. It takes precedence over the built in abbreviations and the
+// ones of the location. It can be given more than once.
+func WithZoneAbbrev(abbrev string, offset time.Duration) Option {
+	return func(o *options) {
+		if o.zones == nil {
+			o.zones = make(map[string]int)
+		}
+
+		o.zones[abbrev] = int(offset / time.Second)
+	}
+}
+
+// WithStrictZones makes time strings with an unknown zone abbreviation fail to
+// parse, instead of being parsed with a zero offset and flagged in Result.
+func WithStrictZones() Option {
+	return func(o *options) {
+		o.strictZones = true
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInLocation(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+
+	// No zone in the string, the location is used
+	actual, err := ParseInLocation("2015-02-06 15:45:16", loc)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2015, 2, 6, 15, 45, 16, 0, loc).UnixNano(), actual.UnixNano())
+	require.Equal(t, loc, actual.Location())
+
+	actual, err = ParseWith("Feb  6 15:45:16", WithLocation(loc))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(0, 2, 6, 15, 45, 16, 0, loc).UnixNano(), actual.UnixNano())
+
+	// Without a year, the offset of the location in the current year, not its
+	// local mean time in year 0
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	actual, err = ParseInLocation("Feb  6 15:45:16", ny)
+	require.NoError(t, err)
+	_, off := actual.Zone()
+	require.Equal(t, -5*3600, off)
+	require.Equal(t, 0, actual.Year())
+	require.Equal(t, time.Date(0, 2, 6, 20, 45, 16, 0, time.UTC).UnixNano(), actual.UnixNano())
+
+	actual, err = ParseInLocation("Jul  6 15:45:16", ny)
+	require.NoError(t, err)
+	_, off = actual.Zone()
+	require.Equal(t, -4*3600, off)
+
+	actual, err = ParseWith("Feb  6 15:45:16", WithLocation(ny), WithReference(time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2015, 2, 6, 20, 45, 16, 0, time.UTC).UnixNano(), actual.UnixNano())
+
+	// An explicit offset in the string wins
+	actual, err = ParseInLocation("2015-02-06 15:45:16 +0100", loc)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2015, 2, 6, 14, 45, 16, 0, time.UTC).UnixNano(), actual.UnixNano())
+
+	actual, err = ParseInLocation("2015-02-06T15:45:16+00:00", loc)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2015, 2, 6, 15, 45, 16, 0, time.UTC).UnixNano(), actual.UnixNano())
+
+	// The default is still UTC
+	actual, err = Parse("2015-02-06 15:45:16")
+	require.NoError(t, err)
+	require.Equal(t, time.UTC, actual.Location())
+
+	actual, err = ParseInLocation("2015-02-06 15:45:16", nil)
+	require.NoError(t, err)
+	require.Equal(t, time.UTC, actual.Location())
+}
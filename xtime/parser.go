@@ -0,0 +1,380 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Parser parses time strings using its own list of formats, which can be extended
+// at any time with Add. It is safe for concurrent use.
+type Parser struct {
+	mu      sync.RWMutex
+	formats []string
+	layouts [][]layoutElem // The elements of each of the formats
+	dates   []*dateLayouts // The date layouts of each of the formats, nil if no numeric date
+	fast    []fastFunc     // The fast converter of each of the formats, nil if none
+	custom  []customFunc   // The converter of each of the formats the time package can't parse, nil if none
+	fields  []Fields       // The fields of each of the formats
+	root    *timeNode
+	opts    []Option
+}
+
+// NewParser returns a parser for the given formats, in the layout format of the time
+// package. Use NewParser(TimeFormats...) to start from the default formats.
+func NewParser(formats ...string) *Parser {
+	p := &Parser{root: &timeNode{}}
+
+	for _, f := range formats {
+		p.add(f)
+	}
+
+	return p
+}
+
+// Add adds a format, in the layout format of the time package, to the parser. The
+// tree is extended in place, so the format is used by the very next parse. Adding
+// a format that the parser already has does nothing.
+func (p *Parser) Add(format string) error {
+	if !isTimeLayout(parseLayout(format)) {
+		return fmt.Errorf("xtime/Add: Invalid time format %q", format)
+	}
+
+	if _, err := time.Parse(format, referenceTime.Format(format)); err != nil {
+		return fmt.Errorf("xtime/Add: Invalid time format %q: %v", format, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, f := range p.formats {
+		if f == format {
+			return nil
+		}
+	}
+
+	p.add(format)
+
+	return nil
+}
+
+// isTimeLayout returns true if the layout has a date or an hour, and is not just
+// literal text or a zone.
+func isTimeLayout(elems []layoutElem) bool {
+	return layoutHas(elems, elemLongYear, elemYear, elemLongMonth, elemMonth, elemNumMonth, elemZeroMonth,
+		elemDay, elemUnderDay, elemZeroDay, elemUnderYearDay, elemZeroYearDay, elemHour, elemHour12,
+		elemZeroHour12)
+}
+
+func (p *Parser) add(format string) {
+	elems := parseLayout(format)
+
+	p.root.insert(elems, len(p.formats))
+	p.formats = append(p.formats, format)
+	p.layouts = append(p.layouts, elems)
+	p.dates = append(p.dates, newDateLayouts(elems))
+	p.fast = append(p.fast, fastLayouts[format])
+	p.custom = append(p.custom, customLayouts[format])
+	p.fields = append(p.fields, layoutFields(format, elems))
+}
+
+// Formats returns a copy of the formats of the parser, in the order they were added.
+func (p *Parser) Formats() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return append([]string(nil), p.formats...)
+}
+
+// SetOptions sets the options used by every parse, such as the default location.
+// Options given to ParseWith are applied after these.
+func (p *Parser) SetOptions(opts ...Option) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.opts = append([]Option(nil), opts...)
+}
+
+// Parse parses the time string using the first of the parser's formats that matches
+// it. If none matches, the time string is parsed as a numeric epoch timestamp.
+func (p *Parser) Parse(t string) (time.Time, error) {
+	return p.ParseWith(t)
+}
+
+// ParseInLocation is like Parse, but times without a zone or offset are returned in
+// the given location.
+func (p *Parser) ParseInLocation(t string, loc *time.Location) (time.Time, error) {
+	return p.ParseWith(t, WithLocation(loc))
+}
+
+// ParseWith is like Parse, but with options that change how the time string is
+// parsed.
+func (p *Parser) ParseWith(t string, opts ...Option) (time.Time, error) {
+	res, err := p.Detect(t, opts...)
+	return res.Time, err
+}
+
+// Detect parses the time string like ParseWith, and also reports which of the
+// parser's formats matched.
+func (p *Parser) Detect(t string, opts ...Option) (Result, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var (
+		o   = newOptions(p.opts, opts)
+		m   = matcher{t: t, o: &o}
+		buf [16]candidate
+		err error
+	)
+
+	for _, c := range m.match(p.root, buf[:0]) {
+		var res Result
+		if res, err = p.parseFormat(t, c.index, &o); err == nil {
+			return res, nil
+		}
+	}
+
+	if err != nil {
+		return Result{Index: -1}, err
+	}
+
+	// Not one of the formats, maybe it's an epoch timestamp
+	tm, unit, err := parseEpoch(t, &o)
+	if err != nil {
+		return Result{Index: -1}, err
+	}
+
+	return epochResult(t, tm, unit), nil
+}
+
+// ParseFormat parses the time string like Parse, and also returns the format that
+// matched, so it can be used for the rest of the input.
+func (p *Parser) ParseFormat(t string) (time.Time, string, error) {
+	res, err := p.Detect(t)
+	return res.Time, res.Layout, err
+}
+
+// DetectAll returns a result for every one of the parser's formats that accepts the
+// time string, in the order of the formats, followed by the epoch timestamp result
+// if the time string is one. Unlike Detect, which stops at the first
+// match, this tries every format, so it is much slower.
+func (p *Parser) DetectAll(t string, opts ...Option) []Result {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var (
+		o   = newOptions(p.opts, opts)
+		all []Result
+	)
+
+	for i := range p.formats {
+		if res, err := p.parseFormat(t, i, &o); err == nil {
+			all = append(all, res)
+		}
+	}
+
+	if tm, unit, err := parseEpoch(t, &o); err == nil {
+		all = append(all, epochResult(t, tm, unit))
+	}
+
+	return all
+}
+
+// parseFormat parses the time string with the format at index i. It must be called
+// with the lock held.
+func (p *Parser) parseFormat(t string, i int, o *options) (Result, error) {
+	d := p.dates[i]
+	if d == nil {
+		return p.parseAs(t, i, p.formats[i], o)
+	}
+
+	// Try the preferred order, then the order of the format, then the others
+	var (
+		orders = []DateOrder{o.order, d.native, DMY, MDY, YMD}
+		res    = Result{Index: -1}
+		err    error
+		tried  [numDateOrders]bool
+	)
+
+	for _, order := range orders {
+		if order == DateOrderAuto || tried[order] || d.layouts[order] == "" {
+			continue
+		}
+
+		tried[order] = true
+
+		r, e := p.parseAs(t, i, d.layouts[order], o)
+		switch {
+		case e != nil:
+			if res.Index == -1 && err == nil {
+				err = e
+			}
+		case res.Index == -1:
+			res, err = r, nil
+		case !r.Time.Equal(res.Time):
+			// Another order gives a different, valid date
+			res.Ambiguous = true
+			return res, nil
+		}
+	}
+
+	return res, err
+}
+
+// parseAs parses the time string with the layout, which is the format at index
+// i or one of its date layouts.
+func (p *Parser) parseAs(t string, i int, layout string, o *options) (Result, error) {
+	var (
+		tm  time.Time
+		ok  bool
+		err error
+	)
+
+	switch {
+	case p.custom[i] != nil:
+		if tm, err = p.custom[i](t, o.loc); err != nil {
+			return Result{Index: -1}, err
+		}
+
+		ok = true
+
+	case p.fast[i] != nil && layout == p.formats[i]:
+		tm, ok = p.fast[i](t, o.loc)
+	}
+
+	if !ok {
+		if tm, err = time.ParseInLocation(layout, translate(p.layouts[i], t, o), o.loc); err != nil {
+			return Result{Index: -1}, err
+		}
+	}
+
+	res := Result{Layout: layout, Index: i, Fields: p.fields[i], Zone: layoutZone(p.layouts[i], tm)}
+
+	if tm.Nanosecond() != 0 {
+		// The time package accepts fractional seconds even if the layout doesn't
+		// have them
+		res.Fields |= FieldFraction
+	}
+
+	if layoutHas(p.layouts[i], elemTZ) {
+		var known bool
+		if tm, known = resolveZone(tm, o); !known {
+			if o.strictZones {
+				return Result{Index: -1}, zoneError(tm)
+			}
+
+			res.UnknownZone = true
+		}
+	}
+
+	// Only dates without a year, a time alone stays on Jan 1 of year 0
+	if !o.ref.IsZero() && !res.Fields.Has(FieldYear) && res.Fields.Has(FieldMonth|FieldDay) {
+		tm = inferYear(tm, o.ref)
+		res.YearInferred = true
+	}
+
+	if tm.Year() == 0 && !res.Fields.Has(FieldYear|FieldZone) {
+		tm = yearZeroOffset(tm, o)
+	}
+
+	res.Time = tm
+	res.Precision = p.precision(t, i, layout, o)
+
+	return res, nil
+}
+
+// yearZeroOffset returns t, which has no year and no zone, with the offset its
+// location has in the year of the reference time, or the current year. Year 0 is
+// before most locations had a standard time, so time.Date gives them their local
+// mean time, such as -04:56 for America/New_York.
+func yearZeroOffset(t time.Time, o *options) time.Time {
+	if t.Location() == time.UTC {
+		return t
+	}
+
+	ref := o.ref
+	if ref.IsZero() {
+		ref = time.Now()
+	}
+
+	name, off := time.Date(ref.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()).Zone()
+
+	return time.Date(0, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.FixedZone(name, off))
+}
+
+// referenceTime is the reference time of the time package, used to check the formats.
+var referenceTime = time.Date(2006, 1, 2, 15, 4, 5, 999999999, time.FixedZone("MST", -7*3600))
+
+// precision returns the number of digits of the fraction of a second in the time
+// string parsed with the layout, which is the format at index i or one of its date
+// layouts. Only .000 fixes it, a .999 fraction or one the time package accepts after
+// the seconds has to be found in the time string.
+func (p *Parser) precision(t string, i int, layout string, o *options) int {
+	elems := p.layouts[i]
+
+	// The seconds, and the fraction if any
+	first, last := -1, -1
+	for j, e := range elems {
+		switch e.kind {
+		case elemFracSecond0:
+			return len(e.value) - 1
+		case elemSecond, elemZeroSecond:
+			first, last = j, j
+		case elemFracSecond9:
+			if first == -1 {
+				first = j
+			}
+
+			last = j
+		}
+	}
+
+	if first == -1 || strings.IndexAny(t, ".,") == -1 {
+		return 0
+	}
+
+	if layout != p.formats[i] {
+		elems = parseLayout(layout)
+	}
+
+	var (
+		buf  [32]int
+		ends = buf[:]
+	)
+
+	if len(elems) > len(buf) {
+		ends = make([]int, len(elems))
+	}
+
+	if !matchElems(elems, t, o, ends, 0) {
+		return 0
+	}
+
+	start := 0
+	if first > 0 {
+		start = ends[first-1]
+	}
+
+	// The seconds can take the fraction before a .999 fraction does
+	s := t[start:ends[last]]
+	if j := strings.IndexAny(s, ".,"); j != -1 {
+		return len(s) - j - 1
+	}
+
+	return 0
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserAdd(t *testing.T) {
+	p := NewParser(TimeFormats...)
+	require.Equal(t, TimeFormats, p.Formats())
+
+	_, err := p.Parse("2015.02.06 15:45:16")
+	require.Error(t, err)
+
+	require.NoError(t, p.Add("2006.01.02 15:04:05"))
+	require.Len(t, p.Formats(), len(TimeFormats)+1)
+
+	actual, err := p.Parse("2015.02.06 15:45:16")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2015, 2, 6, 15, 45, 16, 0, time.UTC).UnixNano(), actual.UnixNano())
+
+	// Adding it again does nothing
+	require.NoError(t, p.Add("2006.01.02 15:04:05"))
+	require.Len(t, p.Formats(), len(TimeFormats)+1)
+
+	// The default parser is not changed
+	_, err = Parse("2015.02.06 15:45:16")
+	require.Error(t, err)
+
+	require.Error(t, p.Add("hello world"))
+	require.Error(t, p.Add(""))
+}
+
+func TestParserOptions(t *testing.T) {
+	loc := time.FixedZone("", 3600)
+
+	p := NewParser("2006-01-02 15:04:05")
+	p.SetOptions(WithLocation(loc))
+
+	actual, err := p.Parse("2015-02-06 15:45:16")
+	require.NoError(t, err)
+	require.Equal(t, loc, actual.Location())
+
+	// Options given to ParseWith win
+	actual, err = p.ParseInLocation("2015-02-06 15:45:16", time.UTC)
+	require.NoError(t, err)
+	require.Equal(t, time.UTC, actual.Location())
+
+	_, err = p.Parse("Feb  6 15:45:16")
+	require.Error(t, err)
+}
+
+func TestParserConcurrent(t *testing.T) {
+	p := NewParser(TimeFormats...)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				_, err := p.Parse("2006-01-02T15:04:05+07:00")
+				require.NoError(t, err)
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, p.Add(fmt.Sprintf("2006.01.02 15:04:05 x%c", 'a'+i)))
+	}
+
+	wg.Wait()
+
+	_, err := p.Parse("2015.02.06 15:45:16 xt")
+	require.NoError(t, err)
+}
@@ -0,0 +1,352 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+var (
+	strftimeLayouts = map[string]string{
+		"Y": "2006", "y": "06",
+		"m": "01", "-m": "1",
+		"d": "02", "-d": "2", "e": "_2", "_d": "_2", "-e": "2",
+		"b": "Jan", "h": "Jan", "B": "January",
+		"a": "Mon", "A": "Monday",
+		"j": "002", "_j": "__2",
+		"H": "15", "-H": "15",
+		"I": "03", "-I": "3",
+		"M": "04", "-M": "4",
+		"S": "05", "-S": "5",
+		"p": "PM", "P": "pm",
+		"Z": "MST", "z": "-0700", ":z": "-07:00",
+		"T": "15:04:05", "R": "15:04", "r": "03:04:05 PM",
+		"D": "01/02/06", "F": "2006-01-02",
+		"c": "Mon Jan _2 15:04:05 2006", "x": "01/02/06", "X": "15:04:05",
+	}
+
+	// The digits of the fractions of a second, after a period or a comma. Python
+	// accepts 1 to 6 digits for %f, so it's a .999999 fraction.
+	strftimeFractions = map[string]string{
+		"f": "999999", "L": "000", "N": "000000000",
+		"3N": "000", "6N": "000000", "9N": "000000000",
+	}
+
+	strftimeLiterals = map[string]string{"%": "%", "n": "\n", "t": "\t"}
+
+	javaLayouts = map[string]string{
+		"y": "2006", "yy": "06", "yyy": "2006", "yyyy": "2006",
+		"u": "2006", "uu": "06", "uuu": "2006", "uuuu": "2006",
+		"M": "1", "MM": "01", "MMM": "Jan", "MMMM": "January",
+		"L": "1", "LL": "01", "LLL": "Jan", "LLLL": "January",
+		"d": "2", "dd": "02", "DDD": "002",
+		"E": "Mon", "EE": "Mon", "EEE": "Mon", "EEEE": "Monday",
+		"a": "PM",
+		"H": "15", "HH": "15",
+		"h": "3", "hh": "03",
+		"m": "4", "mm": "04",
+		"s": "5", "ss": "05",
+		"z": "MST", "zz": "MST", "zzz": "MST",
+		"Z": "-0700", "ZZ": "-0700", "ZZZ": "-0700", "ZZZZZ": "Z07:00",
+		"X": "Z07", "XX": "Z0700", "XXX": "Z07:00",
+		"x": "-07", "xx": "-0700", "xxx": "-07:00",
+	}
+
+	momentLayouts = map[string]string{
+		"YYYY": "2006", "YY": "06",
+		"M": "1", "MM": "01", "MMM": "Jan", "MMMM": "January",
+		"D": "2", "DD": "02", "DDDD": "002",
+		"ddd": "Mon", "dddd": "Monday",
+		"H": "15", "HH": "15",
+		"h": "3", "hh": "03",
+		"m": "4", "mm": "04",
+		"s": "5", "ss": "05",
+		"A": "PM", "a": "pm",
+		"Z": "-07:00", "ZZ": "-0700",
+		"z": "MST", "zz": "MST",
+	}
+
+	// The letters of moment.js tokens, other letters are literal text
+	momentLetters = "YyMDdHhmsSAaZzQWwEeGgXxkNL"
+)
+
+// layoutBuilder builds a layout from the elements of a pattern.
+type layoutBuilder struct {
+	buf         bytes.Buffer
+	kinds       []elemKind
+	unsupported []string
+
+	// The literal text between the elements
+	literals []string
+	lit      bytes.Buffer
+}
+
+func (b *layoutBuilder) literal(s string) {
+	b.buf.WriteString(s)
+	b.lit.WriteString(s)
+}
+
+func (b *layoutBuilder) endLiteral() {
+	if b.lit.Len() > 0 {
+		b.literals = append(b.literals, b.lit.String())
+		b.lit.Reset()
+	}
+}
+
+func (b *layoutBuilder) elem(layout string) {
+	b.endLiteral()
+
+	for _, e := range parseLayout(layout) {
+		if e.kind != elemLiteral {
+			b.kinds = append(b.kinds, e.kind)
+		}
+	}
+
+	b.buf.WriteString(layout)
+}
+
+// fraction adds a fraction of a second, "000" for 3 digits or "999" for up to 3
+// digits, which Go layouts only have after a period or a comma.
+func (b *layoutBuilder) fraction(token, digits string) {
+	if s := b.buf.Bytes(); len(s) == 0 || (s[len(s)-1] != '.' && s[len(s)-1] != ',') {
+		b.unsupport(token)
+		return
+	}
+
+	b.endLiteral()
+
+	if digits[0] == '9' {
+		b.kinds = append(b.kinds, elemFracSecond9)
+	} else {
+		b.kinds = append(b.kinds, elemFracSecond0)
+	}
+
+	b.buf.WriteString(digits)
+}
+
+func (b *layoutBuilder) unsupport(token string) {
+	b.unsupported = append(b.unsupported, fmt.Sprintf("%q", token))
+}
+
+// layout returns the layout, or an error listing the unsupported tokens, or if the
+// literal text in the pattern would be read as layout elements.
+func (b *layoutBuilder) layout(fn, pattern string) (string, error) {
+	if len(b.unsupported) != 0 {
+		return "", fmt.Errorf("xtime/%s: Unsupported %s in %q", fn, strings.Join(b.unsupported, ", "), pattern)
+	}
+
+	layout := b.buf.String()
+
+	var kinds []elemKind
+	for _, e := range parseLayout(layout) {
+		if e.kind != elemLiteral {
+			kinds = append(kinds, e.kind)
+		}
+	}
+
+	same := len(kinds) == len(b.kinds)
+	for i := 0; same && i < len(kinds); i++ {
+		same = kinds[i] == b.kinds[i]
+	}
+
+	if same {
+		return layout, nil
+	}
+
+	// Go layouts can't escape literal text, so name the text that's read as a
+	// time element, such as the 1 of "100"
+	b.endLiteral()
+
+	for _, lit := range b.literals {
+		for _, e := range parseLayout(lit) {
+			if e.kind != elemLiteral {
+				return "", fmt.Errorf("xtime/%s: Literal text %q in %q would be read as the time element %q, Go layouts can't escape it",
+					fn, lit, pattern, e.value)
+			}
+		}
+	}
+
+	return "", fmt.Errorf("xtime/%s: Literal text in %q would be read as a time element with the elements around it", fn, pattern)
+}
+
+// FromStrftime converts a strftime pattern, such as "%Y-%m-%d %H:%M:%S", to a Go layout
+// that can be added to a Parser. The - and _ flags remove or space pad the numbers where
+// Go layouts can, and the fractions %f, %L and %N must follow a period or a comma. %f
+// accepts any number of digits, as Python does up to 6, while %L and %N take exactly 3
+// and 9. Directives without a Go equivalent, such as %s or %U, are reported in the
+// error, as is literal text that Go would read as a time element, such as the 1 of 100.
+func FromStrftime(pattern string) (string, error) {
+	var b layoutBuilder
+
+	for i := 0; i < len(pattern); i++ {
+		k := strings.IndexByte(pattern[i:], '%')
+		if k == -1 {
+			b.literal(pattern[i:])
+			break
+		}
+
+		b.literal(pattern[i : i+k])
+		i += k + 1
+
+		// The optional flag, or the digits of %N, and the directive
+		j := i
+		for j < len(pattern) && strings.IndexByte("-_:0123456789", pattern[j]) != -1 {
+			j++
+		}
+
+		if j == len(pattern) {
+			b.unsupport(pattern[i-1:])
+			break
+		}
+
+		d := pattern[i : j+1]
+		i = j
+
+		if s, ok := strftimeLiterals[d]; ok {
+			b.literal(s)
+		} else if s, ok := strftimeLayouts[d]; ok {
+			b.elem(s)
+		} else if digits, ok := strftimeFractions[d]; ok {
+			b.fraction("%"+d, digits)
+		} else {
+			b.unsupport("%" + d)
+		}
+	}
+
+	return b.layout("FromStrftime", pattern)
+}
+
+// FromJava converts a Java DateTimeFormatter or SimpleDateFormat pattern, such as
+// "yyyy-MM-dd'T'HH:mm:ss.SSSZ", to a Go layout that can be added to a Parser. Text
+// is quoted with ', and the fraction of a second must follow a period or a comma.
+// The letter u is the year, as in DateTimeFormatter. Letters without a Go
+// equivalent, such as w or VV, are reported in the error.
+func FromJava(pattern string) (string, error) {
+	var b layoutBuilder
+
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+
+		switch {
+		case c == '\'':
+			// Quoted text, '' is a quote
+			if hasPrefix(pattern[i:], "''") {
+				b.literal("'")
+				i += 2
+				continue
+			}
+
+			var text bytes.Buffer
+
+			for i++; ; i++ {
+				if i == len(pattern) {
+					b.unsupport("'" + text.String())
+					break
+				}
+
+				if pattern[i] == '\'' {
+					if !hasPrefix(pattern[i:], "''") {
+						i++
+						break
+					}
+
+					i++
+				}
+
+				text.WriteByte(pattern[i])
+			}
+
+			b.literal(text.String())
+
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+			j := i
+			for j < len(pattern) && pattern[j] == c {
+				j++
+			}
+
+			run := pattern[i:j]
+			i = j
+
+			if s, ok := javaLayouts[run]; ok {
+				b.elem(s)
+			} else if c == 'S' {
+				b.fraction(run, strings.Repeat("0", len(run)))
+			} else {
+				b.unsupport(run)
+			}
+
+		default:
+			b.literal(pattern[i : i+1])
+			i++
+		}
+	}
+
+	return b.layout("FromJava", pattern)
+}
+
+// FromMoment converts a moment.js or Day.js format, such as "YYYY-MM-DD HH:mm", to a
+// Go layout that can be added to a Parser. Text is escaped in square brackets, and
+// the fraction of a second must follow a period or a comma. Tokens without a Go
+// equivalent, such as Do or X, are reported in the error.
+func FromMoment(pattern string) (string, error) {
+	var b layoutBuilder
+
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+
+		switch {
+		case c == '[':
+			k := strings.IndexByte(pattern[i:], ']')
+			if k == -1 {
+				b.unsupport(pattern[i:])
+				i = len(pattern)
+				continue
+			}
+
+			b.literal(pattern[i+1 : i+k])
+			i += k + 1
+
+		case strings.IndexByte(momentLetters, c) != -1:
+			j := i
+			for j < len(pattern) && pattern[j] == c {
+				j++
+			}
+
+			// The ordinals, such as Do
+			if j < len(pattern) && pattern[j] == 'o' {
+				j++
+			}
+
+			run := pattern[i:j]
+			i = j
+
+			if s, ok := momentLayouts[run]; ok {
+				b.elem(s)
+			} else if c == 'S' && run[len(run)-1] == 'S' {
+				b.fraction(run, strings.Repeat("0", len(run)))
+			} else {
+				b.unsupport(run)
+			}
+
+		default:
+			b.literal(pattern[i : i+1])
+			i++
+		}
+	}
+
+	return b.layout("FromMoment", pattern)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromStrftime(t *testing.T) {
+	tests := map[string]string{
+		"%Y-%m-%d %H:%M:%S":        "2006-01-02 15:04:05",
+		"%Y-%m-%dT%H:%M:%S.%f%z":   "2006-01-02T15:04:05.999999-0700",
+		"%Y-%m-%d %H:%M:%S,%L":     "2006-01-02 15:04:05,000",
+		"%d/%b/%Y:%T %z":           "02/Jan/2006:15:04:05 -0700",
+		"%a %b %e %T %Z %Y":        "Mon Jan _2 15:04:05 MST 2006",
+		"%A, %B %-d, %Y %-I:%M %p": "Monday, January 2, 2006 3:04 PM",
+		"%F %R%:z":                 "2006-01-02 15:04-07:00",
+		"%Y.%j":                    "2006.002",
+		"%c":                       "Mon Jan _2 15:04:05 2006",
+		"at %H%t%S.%3N %%":         "at 15\t05.000 %",
+	}
+
+	for in, expected := range tests {
+		actual, err := FromStrftime(in)
+		require.NoError(t, err, in)
+		require.Equal(t, expected, actual, in)
+	}
+
+	for _, in := range []string{"%s", "%Y-%U", "%Y%", "%S%f", "%k:%M", "at 1 %H", "100%% %H"} {
+		_, err := FromStrftime(in)
+		require.Error(t, err, in)
+	}
+
+	_, err := FromStrftime("%Y %U %s")
+	require.Contains(t, err.Error(), `"%U", "%s"`)
+
+	// The literal text that Go would read as a time element is named
+	_, err = FromStrftime("100%% %Y")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `Literal text "100% " in "100%% %Y" would be read as the time element "1"`)
+
+	_, err = FromJava("'Mon' yyyy")
+	require.Contains(t, err.Error(), `Literal text "Mon " in`)
+
+	// Any number of digits for %f, as in Python
+	layout, err := FromStrftime("%Y-%m-%d %H:%M:%S.%f")
+	require.NoError(t, err)
+
+	p := NewParser()
+	require.NoError(t, p.Add(layout))
+
+	for in, nsec := range map[string]int{"2015-02-06 15:45:16.5": 5e8, "2015-02-06 15:45:16.123": 123e6, "2015-02-06 15:45:16.123456": 123456e3} {
+		tm, err := p.Parse(in)
+		require.NoError(t, err, in)
+		require.Equal(t, nsec, tm.Nanosecond(), in)
+	}
+}
+
+func TestFromJava(t *testing.T) {
+	tests := map[string]string{
+		"yyyy-MM-dd HH:mm:ss":           "2006-01-02 15:04:05",
+		"yyyy-MM-dd'T'HH:mm:ss.SSSZ":    "2006-01-02T15:04:05.000-0700",
+		"yyyy-MM-dd'T'HH:mm:ss.SSSXXX":  "2006-01-02T15:04:05.000Z07:00",
+		"dd/MMM/yyyy:HH:mm:ss Z":        "02/Jan/2006:15:04:05 -0700",
+		"EEE, d MMM yyyy HH:mm:ss z":    "Mon, 2 Jan 2006 15:04:05 MST",
+		"EEEE, MMMM d, uuuu h:mm a":     "Monday, January 2, 2006 3:04 PM",
+		"yy.DDD 'o''clock' hh":          "06.002 o'clock 03",
+		"''yyyy''":                      "'2006'",
+		"yyyy-MM-dd HH:mm:ss,SSSSSSSSS": "2006-01-02 15:04:05,000000000",
+	}
+
+	for in, expected := range tests {
+		actual, err := FromJava(in)
+		require.NoError(t, err, in)
+		require.Equal(t, expected, actual, in)
+	}
+
+	for _, in := range []string{"yyyy-ww", "yyyy-MM-ddTHH", "VV", "HH:mm:ssSSS", "yyyy 'unterminated", "yyyyy", "'Mon' yyyy"} {
+		_, err := FromJava(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestFromMoment(t *testing.T) {
+	tests := map[string]string{
+		"YYYY-MM-DD HH:mm":            "2006-01-02 15:04",
+		"YYYY-MM-DDTHH:mm:ss.SSSZ":    "2006-01-02T15:04:05.000-07:00",
+		"ddd, D MMM YYYY HH:mm:ss ZZ": "Mon, 2 Jan 2006 15:04:05 -0700",
+		"dddd MMMM D YYYY h:mm a":     "Monday January 2 2006 3:04 pm",
+		"[Today is] dddd":             "Today is Monday",
+		"YY.DDDD hh:mm A z":           "06.002 03:04 PM MST",
+	}
+
+	for in, expected := range tests {
+		actual, err := FromMoment(in)
+		require.NoError(t, err, in)
+		require.Equal(t, expected, actual, in)
+	}
+
+	for _, in := range []string{"Do MMM YYYY", "X", "x", "YYYY-[MM", "Q", "LT", "[Mon] YYYY", "HH:mm:ssSSS", "DDD"} {
+		_, err := FromMoment(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestFromPatternParser(t *testing.T) {
+	expected := time.Date(2015, 2, 6, 15, 45, 16, 123000000, time.UTC)
+
+	for _, f := range []func() (string, error){
+		func() (string, error) { return FromStrftime("%d.%m.%Y %H:%M:%S.%L") },
+		func() (string, error) { return FromJava("dd.MM.yyyy HH:mm:ss.SSS") },
+		func() (string, error) { return FromMoment("DD.MM.YYYY HH:mm:ss.SSS") },
+	} {
+		layout, err := f()
+		require.NoError(t, err)
+
+		p := NewParser()
+		require.NoError(t, p.Add(layout))
+
+		actual, err := p.Parse("06.02.2015 15:45:16.123")
+		require.NoError(t, err, layout)
+		require.Equal(t, expected.UnixNano(), actual.UnixNano(), layout)
+	}
+}
@@ -0,0 +1,480 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period is an ISO 8601 duration, such as P1Y2M10DT2H30M. The years, months and days
+// are calendar units, so a period of one month added to Jan 31 and to Feb 1 differs
+// in length. Weeks are counted as 7 days.
+type Period struct {
+	Years, Months, Days int
+
+	// The hours, minutes and seconds
+	Time time.Duration
+}
+
+// AddTo returns t with the period added.
+func (p Period) AddTo(t time.Time) time.Time {
+	return t.AddDate(p.Years, p.Months, p.Days).Add(p.Time)
+}
+
+// IsZero reports whether the period is empty.
+func (p Period) IsZero() bool {
+	return p == Period{}
+}
+
+// mul returns the period n times, or false if it overflows.
+func (p Period) mul(n int) (Period, bool) {
+	y, ok1 := mulInt(p.Years, n)
+	m, ok2 := mulInt(p.Months, n)
+	d, ok3 := mulInt(p.Days, n)
+	t, ok4 := mulInt64(int64(p.Time), int64(n))
+
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return Period{}, false
+	}
+
+	return Period{y, m, d, time.Duration(t)}, true
+}
+
+// mulInt returns a * b, or false if it overflows.
+func mulInt(a, b int) (int, bool) {
+	c, ok := mulInt64(int64(a), int64(b))
+	return int(c), ok && int64(int(c)) == c
+}
+
+// mulInt64 returns a * b, or false if it overflows.
+func mulInt64(a, b int64) (int64, bool) {
+	const minInt64 = -1 << 63
+
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	c := a * b
+	if c/b != a || (a == -1 && b == minInt64) || (b == -1 && a == minInt64) {
+		return 0, false
+	}
+
+	return c, true
+}
+
+// String returns the period in the ISO 8601 format, such as P1Y2M10DT2H30M, or PT0S
+// if it's empty.
+func (p Period) String() string {
+	if p.IsZero() {
+		return "PT0S"
+	}
+
+	var buf bytes.Buffer
+
+	if p.Years <= 0 && p.Months <= 0 && p.Days <= 0 && p.Time <= 0 {
+		if q, ok := p.mul(-1); ok {
+			buf.WriteByte('-')
+			p = q
+		}
+	}
+
+	buf.WriteByte('P')
+
+	for _, c := range []struct {
+		v    int
+		unit byte
+	}{{p.Years, 'Y'}, {p.Months, 'M'}, {p.Days, 'D'}} {
+		if c.v != 0 {
+			buf.WriteString(strconv.Itoa(c.v))
+			buf.WriteByte(c.unit)
+		}
+	}
+
+	if p.Time == 0 {
+		return buf.String()
+	}
+
+	buf.WriteByte('T')
+
+	d := p.Time
+	if h := d / time.Hour; h != 0 {
+		buf.WriteString(strconv.FormatInt(int64(h), 10) + "H")
+		d -= h * time.Hour
+	}
+
+	if m := d / time.Minute; m != 0 {
+		buf.WriteString(strconv.FormatInt(int64(m), 10) + "M")
+		d -= m * time.Minute
+	}
+
+	if d != 0 {
+		buf.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S")
+	}
+
+	return buf.String()
+}
+
+// ParsePeriod parses an ISO 8601 duration, such as P1Y2M10DT2H30M, P2W or PT1.5S.
+// A leading minus sign negates the period. Only the hours, minutes and seconds can
+// have a fraction, and only in the last component.
+func ParsePeriod(s string) (Period, error) {
+	p, ok := parsePeriod(s)
+	if !ok {
+		return Period{}, fmt.Errorf("xtime/ParsePeriod: Invalid period %q", s)
+	}
+
+	return p, nil
+}
+
+func parsePeriod(s string) (Period, bool) {
+	var (
+		p    Period
+		sign = 1
+	)
+
+	if hasPrefix(s, "-") {
+		sign, s = -1, s[1:]
+	}
+
+	if !hasPrefix(s, "P") || len(s) < 3 {
+		return Period{}, false
+	}
+
+	s = s[1:]
+
+	var (
+		units = "YMWD"
+		inT   bool
+		frac  bool
+	)
+
+	for s != "" {
+		if s[0] == 'T' {
+			if inT || len(s) == 1 {
+				return Period{}, false
+			}
+
+			units, inT, s = "HMS", true, s[1:]
+			continue
+		}
+
+		// A fraction is only allowed in the last component
+		if frac {
+			return Period{}, false
+		}
+
+		i := 0
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+
+		whole, f := s[:i], ""
+		if i < len(s) && (s[i] == '.' || s[i] == ',') {
+			j := i + 1
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+
+			f, i, frac = s[i+1:j], j, true
+		}
+
+		if whole == "" || i == len(s) {
+			return Period{}, false
+		}
+
+		// The units must be in order, and each only once
+		k := strings.IndexByte(units, s[i])
+		if k == -1 {
+			return Period{}, false
+		}
+
+		unit := units[k]
+		units, s = units[k+1:], s[i+1:]
+
+		v, ok := atoi(whole)
+		if !ok || (frac && !inT) {
+			return Period{}, false
+		}
+
+		switch {
+		case !inT && unit == 'Y':
+			p.Years = v
+		case !inT && unit == 'M':
+			p.Months = v
+		case !inT && unit == 'W':
+			p.Days += 7 * v
+		case !inT && unit == 'D':
+			p.Days += v
+		default:
+			u := time.Second
+			switch unit {
+			case 'H':
+				u = time.Hour
+			case 'M':
+				u = time.Minute
+			}
+
+			if time.Duration(v) > maxDuration/u {
+				return Period{}, false
+			}
+
+			d := time.Duration(v)*u + fracDuration(f, u)
+			if p.Time > maxDuration-d {
+				return Period{}, false
+			}
+
+			p.Time += d
+		}
+	}
+
+	return p.mul(sign)
+}
+
+// Interval is an ISO 8601 time interval. Start and End are always set, and Period is
+// the period given in the interval, or the duration between Start and End if the
+// interval was given as a start and an end.
+type Interval struct {
+	Start, End time.Time
+	Period     Period
+}
+
+// Duration returns the length of the interval.
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// ParseInterval parses an ISO 8601 time interval given as a start and an end, a start
+// and a period, or a period and an end, such as 2007-03-01T13:00:00Z/2008-05-11T15:30:00Z,
+// 2007-03-01T13:00:00Z/P1M or P1M/2008-05-11T15:30:00Z. The start and the end are
+// parsed with ParseWith and the options. The end can leave out the fields it shares
+// with an ISO 8601 start, such as 2007-03-01T13:00:00Z/15:30 or 2008-02-15/03-14.
+func ParseInterval(s string, opts ...Option) (Interval, error) {
+	return defaultParser.ParseInterval(s, opts...)
+}
+
+// ParseInterval is like the package level ParseInterval, but parses the start and the
+// end with the parser's formats.
+func (p *Parser) ParseInterval(s string, opts ...Option) (Interval, error) {
+	i, _, err := p.parseInterval(s, opts)
+	if err != nil {
+		return Interval{}, fmt.Errorf("xtime/ParseInterval: %v", err)
+	}
+
+	return i, nil
+}
+
+// parseInterval parses the interval, and returns whether it was given as a period
+// and an end.
+func (p *Parser) parseInterval(s string, opts []Option) (Interval, bool, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return Interval{}, false, fmt.Errorf("Invalid interval %q", s)
+	}
+
+	start, end := parts[0], parts[1]
+
+	var (
+		i     Interval
+		err   error
+		sp    = hasPrefix(start, "P") || hasPrefix(start, "-P")
+		ep    = hasPrefix(end, "P") || hasPrefix(end, "-P")
+		parse = func(t string) (time.Time, error) {
+			tm, err := p.ParseWith(t, opts...)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("Invalid interval %q: %v", s, err)
+			}
+
+			return tm, nil
+		}
+		period = func(t string) (Period, error) {
+			pd, ok := parsePeriod(t)
+			if !ok {
+				return Period{}, fmt.Errorf("Invalid period %q in interval %q", t, s)
+			}
+
+			return pd, nil
+		}
+	)
+
+	switch {
+	case sp && ep:
+		return Interval{}, false, fmt.Errorf("Invalid interval %q, only one side can be a period", s)
+
+	case sp:
+		if i.Period, err = period(start); err != nil {
+			return Interval{}, false, err
+		}
+
+		if i.End, err = parse(end); err != nil {
+			return Interval{}, false, err
+		}
+
+		neg, ok := i.Period.mul(-1)
+		if !ok {
+			return Interval{}, false, fmt.Errorf("Invalid period %q in interval %q", start, s)
+		}
+
+		i.Start = neg.AddTo(i.End)
+
+	case ep:
+		if i.Start, err = parse(start); err != nil {
+			return Interval{}, false, err
+		}
+
+		if i.Period, err = period(end); err != nil {
+			return Interval{}, false, err
+		}
+
+		i.End = i.Period.AddTo(i.Start)
+
+	default:
+		if i.Start, err = parse(start); err != nil {
+			return Interval{}, false, err
+		}
+
+		if i.End, err = parse(completeEnd(start, end)); err != nil {
+			return Interval{}, false, err
+		}
+
+		i.Period = Period{Time: i.End.Sub(i.Start)}
+	}
+
+	if i.End.Before(i.Start) {
+		return Interval{}, false, fmt.Errorf("Invalid interval %q, the end is before the start", s)
+	}
+
+	return i, sp, nil
+}
+
+// completeEnd returns the end of an interval with the date fields it leaves out taken
+// from the start, such as 2007-03-01T15:30:00Z for 2007-03-01T13:00:00Z/15:30. An end
+// without a zone gets the zone of the start. Only starts with an ISO 8601 date,
+// 2006-01-02, are completed.
+func completeEnd(start, end string) string {
+	if len(end) >= len(start) || len(start) < 10 || start[4] != '-' || start[7] != '-' {
+		return end
+	}
+
+	date, clock := end, ""
+	if k := strings.IndexAny(end, "T "); k != -1 {
+		date, clock = end[:k], end[k:]
+	} else if strings.IndexByte(end, ':') != -1 {
+		date, clock = "", end
+	}
+
+	if len(date) > 10 {
+		return end
+	}
+
+	if clock != "" {
+		rest, zone := start[10:], ""
+		if k := strings.LastIndexAny(rest, "Z+-"); k != -1 {
+			rest, zone = rest[:k], rest[k:]
+		}
+
+		if isDigit(clock[0]) {
+			sep := "T"
+			if rest != "" {
+				sep = rest[:1]
+			}
+
+			clock = sep + clock
+		}
+
+		// The seconds of 15:30 are 0, and the zone is the one of the start
+		if !strings.ContainsAny(clock[1:], "Z+-") {
+			for strings.Count(clock, ":") < strings.Count(rest, ":") {
+				clock += ":00"
+			}
+
+			clock += zone
+		}
+	}
+
+	return start[:10-len(date)] + date + clock
+}
+
+// RepeatingInterval is an ISO 8601 repeating interval, such as R5/2008-03-01T13:00:00Z/P1D.
+// Interval is the first occurrence, or the last if the interval was given as a period
+// and an end.
+type RepeatingInterval struct {
+	Interval
+
+	// The number of occurrences, or -1 if unbounded
+	Repeats int
+
+	fromEnd bool
+}
+
+// Occurrence returns the nth occurrence of the interval, counting from 0, and false if
+// there are no more, or if it's too far to compute. The occurrences are one Period
+// apart, and go back in time if the interval was given as a period and an end.
+func (r RepeatingInterval) Occurrence(n int) (Interval, bool) {
+	if n < 0 || (r.Repeats >= 0 && n >= r.Repeats) {
+		return Interval{}, false
+	}
+
+	if r.fromEnd {
+		n = -n
+	}
+
+	p, ok := r.Period.mul(n)
+	if !ok {
+		return Interval{}, false
+	}
+
+	return Interval{
+		Start:  p.AddTo(r.Start),
+		End:    p.AddTo(r.End),
+		Period: r.Period,
+	}, true
+}
+
+// ParseRepeatingInterval parses an ISO 8601 repeating interval, Rn/ followed by an
+// interval as accepted by ParseInterval. R/ or R-1/ repeat without bounds.
+func ParseRepeatingInterval(s string, opts ...Option) (RepeatingInterval, error) {
+	return defaultParser.ParseRepeatingInterval(s, opts...)
+}
+
+// ParseRepeatingInterval is like the package level ParseRepeatingInterval, but parses
+// the start and the end with the parser's formats.
+func (p *Parser) ParseRepeatingInterval(s string, opts ...Option) (RepeatingInterval, error) {
+	k := strings.IndexByte(s, '/')
+	if !hasPrefix(s, "R") || k == -1 {
+		return RepeatingInterval{}, fmt.Errorf("xtime/ParseRepeatingInterval: Invalid repeating interval %q", s)
+	}
+
+	r := RepeatingInterval{Repeats: -1}
+
+	if n := s[1:k]; n != "" && n != "-1" {
+		v, ok := atoi(n)
+		if !ok {
+			return RepeatingInterval{}, fmt.Errorf("xtime/ParseRepeatingInterval: Invalid number of repetitions in %q", s)
+		}
+
+		r.Repeats = v
+	}
+
+	var err error
+	if r.Interval, r.fromEnd, err = p.parseInterval(s[k+1:], opts); err != nil {
+		return RepeatingInterval{}, fmt.Errorf("xtime/ParseRepeatingInterval: %v", err)
+	}
+
+	return r, nil
+}
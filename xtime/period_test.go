@@ -0,0 +1,192 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected Period
+		str      string
+	}{
+		{"P1Y2M10DT2H30M", Period{1, 2, 10, 2*time.Hour + 30*time.Minute}, "P1Y2M10DT2H30M"},
+		{"P3W", Period{Days: 21}, "P21D"},
+		{"P1W2D", Period{Days: 9}, "P9D"},
+		{"PT36H", Period{Time: 36 * time.Hour}, "PT36H"},
+		{"PT1.5S", Period{Time: 1500 * time.Millisecond}, "PT1.5S"},
+		{"PT0,5H", Period{Time: 30 * time.Minute}, "PT30M"},
+		{"PT1M0.000000001S", Period{Time: time.Minute + 1}, "PT1M0.000000001S"},
+		{"P0D", Period{}, "PT0S"},
+		{"-P1DT1H", Period{Days: -1, Time: -time.Hour}, "-P1DT1H"},
+	}
+
+	for _, tt := range tests {
+		actual, err := ParsePeriod(tt.in)
+		require.NoError(t, err, tt.in)
+		require.Equal(t, tt.expected, actual, tt.in)
+		require.Equal(t, tt.str, actual.String(), tt.in)
+	}
+
+	for _, in := range []string{"", "P", "PT", "P1", "1D", "P1H", "PT1D", "P1DT", "P1M1Y", "P1D1D", "P1.5D", "PT1.5M1S", "PT.5S", "P1DTT1H", "p1d"} {
+		_, err := ParsePeriod(in)
+		require.Error(t, err, in)
+	}
+
+	// Calendar aware
+	p, _ := ParsePeriod("P1M")
+	require.Equal(t, time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC), p.AddTo(time.Date(2015, 2, 1, 0, 0, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2015, 4, 1, 0, 0, 0, 0, time.UTC), p.AddTo(time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestParseInterval(t *testing.T) {
+	start := time.Date(2007, 3, 1, 13, 0, 0, 0, time.UTC)
+	end := time.Date(2008, 5, 11, 15, 30, 0, 0, time.UTC)
+
+	i, err := ParseInterval("2007-03-01T13:00:00Z/2008-05-11T15:30:00Z")
+	require.NoError(t, err)
+	require.Equal(t, start.UnixNano(), i.Start.UnixNano())
+	require.Equal(t, end.UnixNano(), i.End.UnixNano())
+	require.Equal(t, Period{Time: end.Sub(start)}, i.Period)
+	require.Equal(t, end.Sub(start), i.Duration())
+
+	i, err = ParseInterval("2007-03-01T13:00:00Z/P1Y2M10DT2H30M")
+	require.NoError(t, err)
+	require.Equal(t, start.UnixNano(), i.Start.UnixNano())
+	require.Equal(t, end.UnixNano(), i.End.UnixNano())
+	require.Equal(t, Period{1, 2, 10, 2*time.Hour + 30*time.Minute}, i.Period)
+
+	i, err = ParseInterval("P1Y2M10DT2H30M/2008-05-11T15:30:00Z")
+	require.NoError(t, err)
+	require.Equal(t, start.UnixNano(), i.Start.UnixNano())
+	require.Equal(t, end.UnixNano(), i.End.UnixNano())
+
+	// An abbreviated end takes the fields it leaves out from the start
+	for in, expected := range map[string]time.Time{
+		"2007-03-01T13:00:00Z/15:30":                time.Date(2007, 3, 1, 15, 30, 0, 0, time.UTC),
+		"2007-03-01T13:00:00Z/15:30:00":             time.Date(2007, 3, 1, 15, 30, 0, 0, time.UTC),
+		"2007-11-13T09:00:00+01:00/15T17:00:00":     time.Date(2007, 11, 15, 16, 0, 0, 0, time.UTC),
+		"2007-12-14 13:30:00/15:30:00":              time.Date(2007, 12, 14, 15, 30, 0, 0, time.UTC),
+		"2008-02-15/03-14":                          time.Date(2008, 3, 14, 0, 0, 0, 0, time.UTC),
+		"2007-03-01T13:00:00Z/2007-03-01T15:30:00Z": time.Date(2007, 3, 1, 15, 30, 0, 0, time.UTC),
+	} {
+		i, err = ParseInterval(in)
+		require.NoError(t, err, in)
+		require.Equal(t, expected.UnixNano(), i.End.UnixNano(), in)
+	}
+
+	// The endpoints are parsed with the options
+	loc := time.FixedZone("UTC+1", 3600)
+	i, err = ParseInterval("2007-03-01 13:00:00/PT1H", WithLocation(loc))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2007, 3, 1, 14, 0, 0, 0, loc).UnixNano(), i.End.UnixNano())
+
+	for _, in := range []string{"", "2007-03-01T13:00:00Z", "P1D/P1D", "2007-03-01T13:00:00Z/P1X", "foo/P1D", "2008-05-11T15:30:00Z/2007-03-01T13:00:00Z", "a/b/c", "2007-03-01T13:00:00Z/12:00", "Feb  6 15:45:16/15:50:00"} {
+		_, err := ParseInterval(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestParseRepeatingInterval(t *testing.T) {
+	start := time.Date(2008, 3, 1, 13, 0, 0, 0, time.UTC)
+
+	r, err := ParseRepeatingInterval("R5/2008-03-01T13:00:00Z/PT1H")
+	require.NoError(t, err)
+	require.Equal(t, 5, r.Repeats)
+	require.Equal(t, start.UnixNano(), r.Start.UnixNano())
+
+	for n := 0; n < 5; n++ {
+		i, ok := r.Occurrence(n)
+		require.True(t, ok)
+		require.Equal(t, start.Add(time.Duration(n)*time.Hour).UnixNano(), i.Start.UnixNano())
+		require.Equal(t, time.Hour, i.Duration())
+	}
+
+	_, ok := r.Occurrence(5)
+	require.False(t, ok)
+	_, ok = r.Occurrence(-1)
+	require.False(t, ok)
+
+	// Months from the same start, not from the previous occurrence
+	r, err = ParseRepeatingInterval("R/2015-01-31T00:00:00Z/P1M")
+	require.NoError(t, err)
+	require.Equal(t, -1, r.Repeats)
+	i, ok := r.Occurrence(2)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2015, 3, 31, 0, 0, 0, 0, time.UTC).UnixNano(), i.Start.UnixNano())
+
+	// Back in time from the end
+	r, err = ParseRepeatingInterval("R-1/P1D/2015-02-06T00:00:00Z")
+	require.NoError(t, err)
+	require.Equal(t, -1, r.Repeats)
+	i, ok = r.Occurrence(1)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2015, 2, 4, 0, 0, 0, 0, time.UTC).UnixNano(), i.Start.UnixNano())
+	require.Equal(t, time.Date(2015, 2, 5, 0, 0, 0, 0, time.UTC).UnixNano(), i.End.UnixNano())
+
+	for _, in := range []string{"", "R5", "5/2008-03-01T13:00:00Z/PT1H", "Rx/2008-03-01T13:00:00Z/PT1H", "R5/2008-03-01T13:00:00Z"} {
+		_, err := ParseRepeatingInterval(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestPeriodOverflow(t *testing.T) {
+	p, err := ParsePeriod("PT2562047H47M16.854775807S")
+	require.NoError(t, err)
+	require.Equal(t, maxDuration, p.Time)
+
+	for _, in := range []string{"PT999999999H", "PT2562047H48M", "PT2562047H47M16.854775808S", "PT1H153722867M"} {
+		_, err := ParsePeriod(in)
+		require.Error(t, err, in)
+
+		_, err = ParseInterval("2007-03-01T13:00:00Z/" + in)
+		require.Error(t, err, in)
+
+		_, err = ParseRepeatingInterval("R5/2007-03-01T13:00:00Z/" + in)
+		require.Error(t, err, in)
+	}
+
+	r, err := ParseRepeatingInterval("R/2007-03-01T13:00:00Z/PT1H")
+	require.NoError(t, err)
+
+	_, ok := r.Occurrence(1 << 62)
+	require.False(t, ok)
+
+	_, ok = r.Occurrence(1000)
+	require.True(t, ok)
+
+	r, err = ParseRepeatingInterval("R/2007-03-01T13:00:00Z/P1Y")
+	require.NoError(t, err)
+
+	_, ok = r.Occurrence(1 << 62)
+	require.True(t, ok)
+
+	_, ok = r.Occurrence(1<<63 - 1)
+	require.True(t, ok)
+
+	_, ok = (RepeatingInterval{Interval: Interval{Period: Period{Years: 2}}, Repeats: -1}).Occurrence(1<<63 - 1)
+	require.False(t, ok)
+
+	_, ok = mulInt64(-1, -1<<63)
+	require.False(t, ok)
+
+	_, ok = mulInt64(-1<<63, -1)
+	require.False(t, ok)
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParsePrefix parses the time at the start of the string, such as a log line, and
+// returns the time and the number of bytes it takes. See Parser.ParsePrefix.
+func ParsePrefix(t string, opts ...Option) (time.Time, int, error) {
+	return defaultParser.ParsePrefix(t, opts...)
+}
+
+// Find finds the first time in the line, and returns the time and where it starts
+// and ends. See Parser.Find.
+func Find(line string, opts ...Option) (time.Time, int, int, error) {
+	return defaultParser.Find(line, opts...)
+}
+
+// ParsePrefix parses the time at the start of the string, such as a log line, and
+// returns the time and the number of bytes it takes. The longest matching time is
+// used, and it must not be followed by a digit, so "2015-02-06 15:45:16 INFO
+// started" returns the time and 19. A date alone must not be followed by a time of
+// day no format accepts, so "2015-02-06 15:45 INFO" is an error rather than
+// midnight. If no format matches, a leading epoch timestamp is tried.
+func (p *Parser) ParsePrefix(t string, opts ...Option) (time.Time, int, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	o := newOptions(p.opts, opts)
+
+	if res, n, ok := p.parsePrefix(t, &o); ok {
+		return res.Time, n, nil
+	}
+
+	if tm, n, ok := parseEpochPrefix(t, &o); ok {
+		return tm, n, nil
+	}
+
+	return time.Time{}, 0, fmt.Errorf("Unknown time format")
+}
+
+// Find finds the first time in the line, and returns the time and where it starts
+// and ends, so line[start:end] is the time string. A time with both a date and a time
+// of day wins over an earlier date or time alone, otherwise the first one is used, so
+// "at 10:00 on 2015-02-06" returns 10:00. Times are only looked for at the start of
+// the line and after characters that are not letters or digits. Epoch timestamps are
+// only considered if there's no formatted time in the line.
+func (p *Parser) Find(line string, opts ...Option) (time.Time, int, int, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var (
+		o          = newOptions(p.opts, opts)
+		best       time.Time
+		start, end = -1, -1
+	)
+
+	for i := 0; i < len(line); i++ {
+		if !wordStart(line, i) {
+			continue
+		}
+
+		res, n, ok := p.parsePrefix(line[i:], &o)
+		if !ok {
+			// Not the time inside a rejected one either
+			if n > 0 {
+				i += n - 1
+			}
+
+			continue
+		}
+
+		if res.Fields.Has(FieldMonth | FieldDay | FieldHour) {
+			return res.Time, i, i + n, nil
+		}
+
+		if start == -1 {
+			best, start, end = res.Time, i, i+n
+		}
+	}
+
+	if start != -1 {
+		return best, start, end, nil
+	}
+
+	for i := 0; i < len(line); i++ {
+		if !wordStart(line, i) {
+			continue
+		}
+
+		if tm, n, ok := parseEpochPrefix(line[i:], &o); ok {
+			return tm, i, i + n, nil
+		}
+	}
+
+	return time.Time{}, -1, -1, fmt.Errorf("Unknown time format")
+}
+
+// parsePrefix returns the longest time at the start of t that one of the formats
+// accepts. Once a longer match is rejected, such as the time in "2015-02-06 15:45:167",
+// the shorter ones are too, so a malformed time isn't read as the date alone. For the
+// same reason a date alone is rejected if a time of day follows it. If no time is
+// accepted, it returns the end of the longest rejected one, or 0. It must be called
+// with the lock held.
+func (p *Parser) parsePrefix(t string, o *options) (Result, int, bool) {
+	var (
+		m        = matcher{t: t, o: o, prefix: true}
+		buf      [16]candidate
+		rejected = 0 // The end of the longest rejected match
+	)
+
+	// The candidates are sorted longest first
+	for _, c := range m.match(p.root, buf[:0]) {
+		if c.end < rejected {
+			break
+		}
+
+		if c.end < len(t) && isDigit(t[c.end]) {
+			rejected = c.end
+			continue
+		}
+
+		res, err := p.parseFormat(t[:c.end], c.index, o)
+		if err != nil {
+			rejected = c.end
+			continue
+		}
+
+		if res.Fields&FieldTime == 0 {
+			if n := clockLen(t[c.end:]); n > 0 {
+				rejected = c.end + n
+				break
+			}
+		}
+
+		return res, c.end, true
+	}
+
+	return Result{}, rejected, false
+}
+
+// parseEpochPrefix parses the epoch timestamp at the start of t, which must be
+// followed by the end of t or a character that's not a letter or digit.
+func parseEpochPrefix(t string, o *options) (time.Time, int, bool) {
+	n := 0
+	for n < len(t) && (isDigit(t[n]) || (t[n] == '.' && n+1 < len(t) && isDigit(t[n+1]))) {
+		n++
+	}
+
+	if n == 0 || (n < len(t) && isAlnum(t[n])) {
+		return time.Time{}, 0, false
+	}
+
+	tm, _, err := parseEpoch(t[:n], o)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+
+	return tm, n, true
+}
+
+// clockLen returns the length of the time of day at the start of t that follows a
+// date, such as " 15:45" or "T15:45:16", or 0 if there's none.
+func clockLen(t string) int {
+	i := 0
+	for i < len(t) && (t[i] == ' ' || t[i] == 'T') {
+		i++
+	}
+
+	j := i
+	for j < len(t) && j-i < 2 && isDigit(t[j]) {
+		j++
+	}
+
+	if i == 0 || j == i || j+1 >= len(t) || t[j] != ':' || !isDigit(t[j+1]) {
+		return 0
+	}
+
+	for j < len(t) && (isDigit(t[j]) || t[j] == ':' || t[j] == '.' || t[j] == ',') {
+		j++
+	}
+
+	return j
+}
+
+// wordStart returns true if a time could start at position i of the line.
+func wordStart(line string, i int) bool {
+	return i == 0 || (!isAlnum(line[i-1]) && line[i-1] < 0x80)
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
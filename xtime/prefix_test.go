@@ -0,0 +1,104 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		in       string
+		n        int
+		expected time.Time
+	}{
+		{"2015-02-06 15:45:16 INFO started", 19, time.Date(2015, 2, 6, 15, 45, 16, 0, time.UTC)},
+		{"2015-02-06 15:45:16,123 INFO started", 23, time.Date(2015, 2, 6, 15, 45, 16, 123000000, time.UTC)},
+		{"2015-02-06T15:45:16+01:00 started", 25, time.Date(2015, 2, 6, 14, 45, 16, 0, time.UTC)},
+		{"Feb  6 15:45:16 myhost sshd[123]: started", 15, time.Date(0, 2, 6, 15, 45, 16, 0, time.UTC)},
+		{"2015-02-06 15:45:16", 19, time.Date(2015, 2, 6, 15, 45, 16, 0, time.UTC)},
+		{"1696000000 started", 10, time.Unix(1696000000, 0)},
+		{"1696000000.5: started", 12, time.Unix(1696000000, 500000000)},
+	}
+
+	for _, tt := range tests {
+		actual, n, err := ParsePrefix(tt.in)
+		require.NoError(t, err, tt.in)
+		require.Equal(t, tt.n, n, tt.in)
+		require.Equal(t, tt.expected.UnixNano(), actual.UnixNano(), tt.in)
+	}
+
+	for _, in := range []string{"INFO 2015-02-06 15:45:16", "", "1696000000x", "2015-02-06 15:45:167", "2015-02-067"} {
+		_, _, err := ParsePrefix(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestParsePrefixShorter(t *testing.T) {
+	// Dates and times alone still match when nothing longer does
+	for in, n := range map[string]int{"2015-02-06 INFO started": 10, "10:30 started": 5, "10:30:15: started": 8} {
+		_, actual, err := ParsePrefix(in)
+		require.NoError(t, err, in)
+		require.Equal(t, n, actual, in)
+	}
+
+	// But not in place of a longer, malformed time
+	for _, in := range []string{"2015-02-06 15:45:167 started", "2015-02-06 25:45:16 started", "10:307 started", "10:30:157",
+		"2015-02-06 15:45 INFO", "2015-02-06T15:45 INFO", "2015-02-06 9:45 INFO"} {
+		_, _, err := ParsePrefix(in)
+		require.Error(t, err, in)
+
+		_, _, _, err = Find("at " + in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		in         string
+		start, end int
+		expected   time.Time
+	}{
+		{"2015-02-06 15:45:16 INFO started", 0, 19, time.Date(2015, 2, 6, 15, 45, 16, 0, time.UTC)},
+		{"INFO [2015-02-06 15:45:16,123] started", 6, 29, time.Date(2015, 2, 6, 15, 45, 16, 123000000, time.UTC)},
+		{`127.0.0.1 - - [06/Feb/2015:15:45:16 -0700] "GET / HTTP/1.1" 200`, 15, 41, time.Date(2015, 2, 6, 22, 45, 16, 0, time.UTC)},
+		{"pid=1234 ts=1696000000 msg=started", 12, 22, time.Unix(1696000000, 0)},
+
+		// The first time wins, unless a later one has both a date and a time of day
+		{"at 10:00 on 2015-02-06", 3, 8, time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)},
+		{"on 2015-02-06 at 10:00", 3, 13, time.Date(2015, 2, 6, 0, 0, 0, 0, time.UTC)},
+		{"at 10:00 logged 2015-02-06 15:45:16", 16, 35, time.Date(2015, 2, 6, 15, 45, 16, 0, time.UTC)},
+		{"2015-02-06 15:45:16 retry at 2015-02-06 15:50:00.123", 0, 19, time.Date(2015, 2, 6, 15, 45, 16, 0, time.UTC)},
+
+		// The formatted time wins over the epoch timestamp
+		{"1696000000 at 2015-02-06 15:45:16", 14, 33, time.Date(2015, 2, 6, 15, 45, 16, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		actual, start, end, err := Find(tt.in)
+		require.NoError(t, err, tt.in)
+		require.Equal(t, tt.start, start, tt.in)
+		require.Equal(t, tt.end, end, tt.in)
+		require.Equal(t, tt.expected.UnixNano(), actual.UnixNano(), tt.in)
+	}
+
+	_, start, end, err := Find("no time here, only 42")
+	require.Error(t, err)
+	require.Equal(t, -1, start)
+	require.Equal(t, -1, end)
+}
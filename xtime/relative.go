@@ -0,0 +1,403 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"strings"
+	"time"
+)
+
+// timeUnit is a unit of relative times and durations. Days and weeks have a length,
+// but relative times move them on the calendar, so a day is still a day across a
+// daylight saving change. Months and years only exist on the calendar.
+type timeUnit struct {
+	d      time.Duration
+	days   int
+	months int
+}
+
+var (
+	timeUnits = map[string]timeUnit{}
+
+	// The names of each unit
+	timeUnitNames = []struct {
+		names []string
+		unit  timeUnit
+	}{
+		{[]string{"ns", "nanosecond", "nanoseconds"}, timeUnit{d: time.Nanosecond}},
+		{[]string{"us", "µs", "μs", "microsecond", "microseconds"}, timeUnit{d: time.Microsecond}},
+		{[]string{"ms", "millisecond", "milliseconds"}, timeUnit{d: time.Millisecond}},
+		{[]string{"s", "sec", "secs", "second", "seconds"}, timeUnit{d: time.Second}},
+		{[]string{"m", "min", "mins", "minute", "minutes"}, timeUnit{d: time.Minute}},
+		{[]string{"h", "hr", "hrs", "hour", "hours"}, timeUnit{d: time.Hour}},
+		{[]string{"d", "day", "days"}, timeUnit{d: 24 * time.Hour, days: 1}},
+		{[]string{"w", "wk", "wks", "week", "weeks"}, timeUnit{d: 7 * 24 * time.Hour, days: 7}},
+		{[]string{"mo", "month", "months"}, timeUnit{months: 1}},
+		{[]string{"y", "yr", "yrs", "year", "years"}, timeUnit{months: 12}},
+	}
+)
+
+func init() {
+	for _, u := range timeUnitNames {
+		for _, name := range u.names {
+			timeUnits[name] = u.unit
+		}
+	}
+}
+
+const maxDuration = time.Duration(1<<63 - 1)
+
+// relOffset is an offset from a time, in calendar months and days, and a duration.
+type relOffset struct {
+	months, days int
+	d            time.Duration
+}
+
+func (r relOffset) apply(t time.Time, sign int) time.Time {
+	return t.AddDate(0, sign*r.months, sign*r.days).Add(time.Duration(sign) * r.d)
+}
+
+// ParseRelative parses a time relative to the reference time, such as "now-15m",
+// "5 minutes ago", "in 2 hours", "yesterday 14:00", "last monday" or "2h". A
+// duration alone, such as "2h", is that long before the reference time, as in
+// a --since flag. Anything else is parsed as an absolute time with ParseWith.
+//
+// The reference time is set with WithReference, and is the current time by default.
+// Days start at midnight in the location set with WithLocation, UTC by default.
+func ParseRelative(t string, opts ...Option) (time.Time, error) {
+	return defaultParser.ParseRelative(t, opts...)
+}
+
+// ParseRelative is like the package level ParseRelative, but parses absolute times
+// with the parser's formats.
+func (p *Parser) ParseRelative(t string, opts ...Option) (time.Time, error) {
+	p.mu.RLock()
+	o := newOptions(p.opts, opts)
+	p.mu.RUnlock()
+
+	ref := o.ref
+	if ref.IsZero() {
+		ref = time.Now()
+	}
+
+	if tm, ok := parseRelative(t, ref.In(o.loc)); ok {
+		return tm, nil
+	}
+
+	if o.ref.IsZero() {
+		// Absolute times without a year are in the year of the same reference
+		opts = append(opts[:len(opts):len(opts)], WithReference(ref))
+	}
+
+	return p.ParseWith(t, opts...)
+}
+
+// parseRelative parses the relative time expressions, see ParseRelative.
+func parseRelative(t string, now time.Time) (time.Time, bool) {
+	s := strings.ToLower(strings.TrimSpace(t))
+
+	switch {
+	case s == "now":
+		return now, true
+
+	case hasPrefix(s, "now"):
+		rest := strings.TrimSpace(s[3:])
+		if rest == "" || (rest[0] != '+' && rest[0] != '-') {
+			return time.Time{}, false
+		}
+
+		off, ok := parseOffset(rest[1:])
+		if !ok {
+			return time.Time{}, false
+		}
+
+		if rest[0] == '-' {
+			return off.apply(now, -1), true
+		}
+
+		return off.apply(now, 1), true
+
+	case strings.HasSuffix(s, " ago"):
+		off, ok := parseOffset(s[:len(s)-4])
+		return off.apply(now, -1), ok
+
+	case hasPrefix(s, "in "):
+		off, ok := parseOffset(s[3:])
+		return off.apply(now, 1), ok
+	}
+
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return time.Time{}, false
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var (
+		day  time.Time
+		rest []string
+	)
+
+	switch words[0] {
+	case "today":
+		day, rest = midnight, words[1:]
+
+	case "yesterday":
+		day, rest = midnight.AddDate(0, 0, -1), words[1:]
+
+	case "tomorrow":
+		day, rest = midnight.AddDate(0, 0, 1), words[1:]
+
+	case "last", "next":
+		if len(words) < 2 {
+			return time.Time{}, false
+		}
+
+		wd := English.lookup(elemLongWeekDay, words[1])
+		if wd == -1 {
+			wd = English.lookup(elemWeekDay, words[1])
+		}
+
+		if wd == -1 {
+			return time.Time{}, false
+		}
+
+		// The closest one before or after today, never today
+		n := (wd - int(now.Weekday()) + 7) % 7
+		if words[0] == "last" {
+			n = -((int(now.Weekday()) - wd + 7) % 7)
+			if n == 0 {
+				n = -7
+			}
+		} else if n == 0 {
+			n = 7
+		}
+
+		day, rest = midnight.AddDate(0, 0, n), words[2:]
+
+	default:
+		// A duration alone is in the past
+		off, ok := parseOffset(s)
+		return off.apply(now, -1), ok
+	}
+
+	if len(rest) == 0 {
+		return day, true
+	}
+
+	h, m, sec, ok := parseClock(strings.Join(rest, ""))
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, sec, 0, day.Location()), true
+}
+
+// parseOffset parses a list of amounts and units, such as "15m", "1h30m",
+// "1.5 hours" or "2 days 3 hours". Months and years can't have a fraction.
+func parseOffset(s string) (relOffset, bool) {
+	var off relOffset
+
+	ok := parseAmounts(s, func(v uint64, frac string, u timeUnit) bool {
+		switch {
+		case u.months != 0:
+			if frac != "" || v > uint64(maxCalendarAmount-off.months)/uint64(u.months) {
+				return false
+			}
+
+			off.months += int(v) * u.months
+
+		case u.days != 0 && frac == "":
+			if v > uint64(maxCalendarAmount-off.days)/uint64(u.days) {
+				return false
+			}
+
+			off.days += int(v) * u.days
+
+		default:
+			d, ok := unitDuration(v, frac, u.d, uint64(maxDuration-off.d))
+			if !ok {
+				return false
+			}
+
+			off.d += time.Duration(d)
+		}
+
+		return true
+	})
+
+	if !ok {
+		return relOffset{}, false
+	}
+
+	return off, true
+}
+
+// The largest total of days or months in a relative time, which fits in a 32-bit int
+const maxCalendarAmount = 1<<31 - 1
+
+// parseAmounts calls fn with each amount and unit in s, such as the 1 hour and 30
+// minutes of "1h30m" or "1 hour and 30 minutes", and returns false if s isn't a
+// list of amounts, an amount overflows, or fn returns false.
+func parseAmounts(s string, fn func(v uint64, frac string, u timeUnit) bool) bool {
+	n := 0
+
+	for {
+		s = strings.TrimLeft(s, " ,")
+		if hasPrefix(s, "and ") {
+			s = s[4:]
+		}
+
+		if s == "" {
+			return n > 0
+		}
+
+		// The amount
+		i := 0
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+
+		whole, frac := s[:i], ""
+		if i < len(s) && s[i] == '.' {
+			j := i + 1
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+
+			frac, i = s[i+1:j], j
+		}
+
+		if whole == "" && frac == "" {
+			return false
+		}
+
+		var v uint64
+		for k := 0; k < len(whole); k++ {
+			if v > (1<<64-1-uint64(whole[k]-'0'))/10 {
+				return false
+			}
+
+			v = v*10 + uint64(whole[k]-'0')
+		}
+
+		// The unit
+		s = strings.TrimLeft(s[i:], " ")
+
+		j := 0
+		for j < len(s) && s[j] != ' ' && s[j] != ',' && !isDigit(s[j]) {
+			j++
+		}
+
+		u, ok := timeUnits[s[:j]]
+		if !ok || !fn(v, frac, u) {
+			return false
+		}
+
+		s = s[j:]
+		n++
+	}
+}
+
+// unitDuration returns v and the fraction of the unit, in nanoseconds, or false if
+// it's more than max.
+func unitDuration(v uint64, frac string, unit time.Duration, max uint64) (uint64, bool) {
+	if v > max/uint64(unit) {
+		return 0, false
+	}
+
+	d := v*uint64(unit) + uint64(fracDuration(frac, unit))
+	if d > max {
+		return 0, false
+	}
+
+	return d, true
+}
+
+// parseClock parses a time of day, such as 14:00, 14:00:05, 2pm or 2:30pm.
+func parseClock(s string) (h, m, sec int, ok bool) {
+	pm := -1
+	switch {
+	case strings.HasSuffix(s, "am"):
+		pm, s = 0, s[:len(s)-2]
+	case strings.HasSuffix(s, "pm"):
+		pm, s = 1, s[:len(s)-2]
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 || (len(parts) == 1 && pm == -1) {
+		return 0, 0, 0, false
+	}
+
+	var v [3]int
+	for i, p := range parts {
+		if len(p) == 0 || len(p) > 2 || (i > 0 && len(p) != 2) {
+			return 0, 0, 0, false
+		}
+
+		if v[i], ok = atoi(p); !ok {
+			return 0, 0, 0, false
+		}
+	}
+
+	h, m, sec = v[0], v[1], v[2]
+
+	if pm != -1 {
+		if h < 1 || h > 12 {
+			return 0, 0, 0, false
+		}
+
+		h = h%12 + 12*pm
+	}
+
+	if h > 23 || m > 59 || sec > 59 {
+		return 0, 0, 0, false
+	}
+
+	return h, m, sec, true
+}
+
+// fracDuration returns the fraction, given as its digits, of the unit.
+func fracDuration(frac string, unit time.Duration) time.Duration {
+	var (
+		d     time.Duration
+		scale = unit
+	)
+
+	for i := 0; i < len(frac) && scale > 0; i++ {
+		scale /= 10
+		d += time.Duration(frac[i]-'0') * scale
+	}
+
+	return d
+}
+
+// atoi converts a string of digits, returning false if it's empty or too long.
+func atoi(s string) (int, bool) {
+	if s == "" || len(s) > 9 {
+		return 0, false
+	}
+
+	v := 0
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return 0, false
+		}
+
+		v = v*10 + int(s[i]-'0')
+	}
+
+	return v, true
+}
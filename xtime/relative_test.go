@@ -0,0 +1,106 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRelative(t *testing.T) {
+	// A Wednesday
+	ref := time.Date(2015, 2, 4, 15, 45, 16, 0, time.UTC)
+
+	tests := []struct {
+		in       string
+		expected time.Time
+	}{
+		{"now", ref},
+		{"NOW", ref},
+		{"now-15m", ref.Add(-15 * time.Minute)},
+		{"now - 1h30m", ref.Add(-90 * time.Minute)},
+		{"now+2d", ref.AddDate(0, 0, 2)},
+		{"5 minutes ago", ref.Add(-5 * time.Minute)},
+		{"1 hour and 30 minutes ago", ref.Add(-90 * time.Minute)},
+		{"2 weeks ago", ref.AddDate(0, 0, -14)},
+		{"1 month ago", ref.AddDate(0, -1, 0)},
+		{"in 2 hours", ref.Add(2 * time.Hour)},
+		{"2h", ref.Add(-2 * time.Hour)},
+		{"1.5h", ref.Add(-90 * time.Minute)},
+		{"500ms", ref.Add(-500 * time.Millisecond)},
+		{"1.5 days ago", ref.Add(-36 * time.Hour)},
+		{"10000000000ns ago", ref.Add(-10 * time.Second)},
+		{"today", time.Date(2015, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", time.Date(2015, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{"yesterday 14:00", time.Date(2015, 2, 3, 14, 0, 0, 0, time.UTC)},
+		{"tomorrow 2:30pm", time.Date(2015, 2, 5, 14, 30, 0, 0, time.UTC)},
+		{"today 12 am", time.Date(2015, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"last monday", time.Date(2015, 2, 2, 0, 0, 0, 0, time.UTC)},
+		{"last wednesday", time.Date(2015, 1, 28, 0, 0, 0, 0, time.UTC)},
+		{"last Thu 09:15:30", time.Date(2015, 1, 29, 9, 15, 30, 0, time.UTC)},
+		{"next monday", time.Date(2015, 2, 9, 0, 0, 0, 0, time.UTC)},
+		{"next wednesday", time.Date(2015, 2, 11, 0, 0, 0, 0, time.UTC)},
+
+		// Absolute times
+		{"2015-02-06 15:45:16", time.Date(2015, 2, 6, 15, 45, 16, 0, time.UTC)},
+		{"1696000000", time.Unix(1696000000, 0)},
+	}
+
+	for _, tt := range tests {
+		actual, err := ParseRelative(tt.in, WithReference(ref))
+		require.NoError(t, err, tt.in)
+		require.Equal(t, tt.expected.UnixNano(), actual.UnixNano(), tt.in)
+	}
+
+	for _, in := range []string{"", "now*2", "now-", "ago", "in", "1.5 months ago", "yesterday 25:00", "last", "last fooday", "today 13pm", "5 parsecs ago", "9223372036854775808ns ago", "3000000000 days ago", "200000000 years ago", "2000000000 days and 2000000000 days ago"} {
+		_, err := ParseRelative(in, WithReference(ref))
+		require.Error(t, err, in)
+	}
+}
+
+func TestParseRelativeLocation(t *testing.T) {
+	loc := time.FixedZone("UTC-8", -8*3600)
+	ref := time.Date(2015, 2, 4, 3, 0, 0, 0, time.UTC)
+
+	// Still the 3rd in the location
+	actual, err := ParseRelative("today 14:00", WithReference(ref), WithLocation(loc))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2015, 2, 3, 14, 0, 0, 0, loc).UnixNano(), actual.UnixNano())
+	require.Equal(t, loc, actual.Location())
+
+	// The reference time is the current time by default
+	actual, err = ParseRelative("now-1h")
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(-time.Hour), actual, time.Minute)
+
+	// Also for absolute times without a year
+	now := time.Now().UTC()
+	actual, err = ParseRelative(now.Format(time.Stamp))
+	require.NoError(t, err)
+	require.Equal(t, now.Year(), actual.Year())
+}
+
+func TestAtoi(t *testing.T) {
+	v, ok := atoi("042")
+	require.True(t, ok)
+	require.Equal(t, 42, v)
+
+	for _, in := range []string{"", "4a", "-1", "1234567890"} {
+		_, ok := atoi(in)
+		require.False(t, ok, in)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import "time"
+
+// Session parses the time strings of a single source, such as a log file, where the
+// format rarely changes. It remembers the format of the last time string and tries
+// it first, and only searches all the formats when it doesn't match.
+//
+// A Session is not safe for concurrent use, use one per source and goroutine. The
+// parser it uses can be shared.
+type Session struct {
+	p     *Parser
+	opts  []Option
+	last  int  // Index of the last format, -1 if none
+	epoch bool // The last time string was an epoch timestamp
+	stats SessionStats
+}
+
+// SessionStats are the statistics of a Session.
+type SessionStats struct {
+	Hits     uint64 // Time strings parsed with the last format
+	Misses   uint64 // Time strings that needed a search of all the formats
+	Failures uint64 // Time strings that no format accepts, also counted as misses
+}
+
+// NewSession returns a session that parses with the TimeFormats, see Parse, and the
+// options.
+func NewSession(opts ...Option) *Session {
+	return defaultParser.NewSession(opts...)
+}
+
+// NewSession returns a session that parses with the parser's formats and options,
+// followed by the given options.
+func (p *Parser) NewSession(opts ...Option) *Session {
+	return &Session{p: p, opts: append([]Option(nil), opts...), last: -1}
+}
+
+// Parse parses the time string, trying the last format first.
+func (s *Session) Parse(t string) (time.Time, error) {
+	res, err := s.Detect(t)
+	return res.Time, err
+}
+
+// Detect is like Parse, and also reports which of the formats matched.
+func (s *Session) Detect(t string) (Result, error) {
+	if res, ok := s.parseLast(t); ok {
+		s.stats.Hits++
+		return res, nil
+	}
+
+	s.stats.Misses++
+
+	res, err := s.p.Detect(t, s.opts...)
+	if err != nil {
+		s.stats.Failures++
+		return res, err
+	}
+
+	s.last, s.epoch = res.Index, res.Index == -1
+
+	return res, nil
+}
+
+// Layout returns the last format that matched, empty if none or if it was an epoch
+// timestamp.
+func (s *Session) Layout() string {
+	if s.last == -1 {
+		return ""
+	}
+
+	s.p.mu.RLock()
+	defer s.p.mu.RUnlock()
+
+	return s.p.formats[s.last]
+}
+
+// Stats returns the statistics of the session.
+func (s *Session) Stats() SessionStats {
+	return s.stats
+}
+
+// Reset forgets the last format and clears the statistics.
+func (s *Session) Reset() {
+	s.last, s.epoch, s.stats = -1, false, SessionStats{}
+}
+
+func (s *Session) parseLast(t string) (Result, bool) {
+	if s.last == -1 && !s.epoch {
+		return Result{}, false
+	}
+
+	s.p.mu.RLock()
+	defer s.p.mu.RUnlock()
+
+	o := newOptions(s.p.opts, s.opts)
+
+	if s.epoch {
+		tm, unit, err := parseEpoch(t, &o)
+		return epochResult(t, tm, unit), err == nil
+	}
+
+	res, err := s.p.parseFormat(t, s.last, &o)
+
+	return res, err == nil
+}
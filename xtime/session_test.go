@@ -0,0 +1,82 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	s := NewSession(WithLocation(time.FixedZone("X", 3600)))
+	require.Equal(t, "", s.Layout())
+
+	for i, in := range []string{"2015-02-06 15:45:16", "2015-02-06 15:45:17", "2015-02-06 15:45:18"} {
+		tm, err := s.Parse(in)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2015, 2, 6, 14, 45, 16+i, 0, time.UTC).UnixNano(), tm.UnixNano())
+	}
+
+	require.Equal(t, "2006-01-02 15:04:05", s.Layout())
+	require.Equal(t, SessionStats{Hits: 2, Misses: 1}, s.Stats())
+
+	// A new format, found by searching all the formats, then remembered
+	res, err := s.Detect("Feb  6 15:45:16")
+	require.NoError(t, err)
+	require.Equal(t, "Jan _2 15:04:05", res.Layout)
+
+	_, err = s.Parse("Feb  6 15:45:17")
+	require.NoError(t, err)
+	require.Equal(t, SessionStats{Hits: 3, Misses: 2}, s.Stats())
+
+	_, err = s.Parse("not a time")
+	require.Error(t, err)
+	require.Equal(t, SessionStats{Hits: 3, Misses: 3, Failures: 1}, s.Stats())
+	require.Equal(t, "Jan _2 15:04:05", s.Layout())
+
+	// Epoch timestamps are remembered too
+	res, err = s.Detect("1696000000")
+	require.NoError(t, err)
+	require.Equal(t, EpochSeconds, res.Epoch)
+
+	res, err = s.Detect("1696000001")
+	require.NoError(t, err)
+	require.Equal(t, int64(1696000001), res.Time.Unix())
+	require.Equal(t, "", s.Layout())
+	require.Equal(t, SessionStats{Hits: 4, Misses: 4, Failures: 1}, s.Stats())
+
+	s.Reset()
+	require.Equal(t, SessionStats{}, s.Stats())
+	require.Equal(t, "", s.Layout())
+}
+
+func TestSessionParser(t *testing.T) {
+	p := NewParser()
+	s := p.NewSession()
+
+	_, err := s.Parse("2015.02.06 15:45:16")
+	require.Error(t, err)
+
+	require.NoError(t, p.Add("2006.01.02 15:04:05"))
+
+	_, err = s.Parse("2015.02.06 15:45:16")
+	require.NoError(t, err)
+
+	_, err = s.Parse("2015.02.06 15:45:17")
+	require.NoError(t, err)
+	require.Equal(t, SessionStats{Hits: 1, Misses: 2, Failures: 1}, s.Stats())
+}
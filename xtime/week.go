@@ -0,0 +1,66 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"fmt"
+	"time"
+)
+
+// customFunc converts a time string in a format that the time package can't parse.
+type customFunc func(t string, loc *time.Location) (time.Time, error)
+
+var (
+	// customLayouts are the formats with their own converters. They look like time
+	// package layouts, so they are matched the same way, but their fields mean
+	// something else, such as the ISO week in place of the month.
+	customLayouts = map[string]customFunc{
+		"2006-W01-2": parseISOWeek,
+	}
+)
+
+// parseISOWeek converts an ISO 8601 week date, such as 2006-W01-2 for the Tuesday of
+// the first week of 2006, which is Jan 3. Week 1 is the week with the first Thursday
+// of the year, and weeks start on Monday.
+func parseISOWeek(t string, loc *time.Location) (time.Time, error) {
+	s := scanner{s: t, ok: true}
+
+	year := s.num(4)
+	s.lit('-')
+	s.lit('W')
+	week := s.num(2)
+	s.lit('-')
+	day := s.num(1)
+
+	if !s.ok || s.s != "" {
+		return time.Time{}, fmt.Errorf("xtime/Parse: Invalid ISO week date %q", t)
+	}
+
+	if week < 1 || week > 53 || day < 1 || day > 7 {
+		return time.Time{}, fmt.Errorf("xtime/Parse: ISO week date out of range %q", t)
+	}
+
+	// Jan 4 is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	tm := monday.AddDate(0, 0, (week-1)*7+day-1)
+
+	if y, w := tm.ISOWeek(); y != year || w != week {
+		// Week 53 of a year with 52 weeks
+		return time.Time{}, fmt.Errorf("xtime/Parse: ISO week date out of range %q", t)
+	}
+
+	return tm, nil
+}
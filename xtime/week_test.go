@@ -0,0 +1,54 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestISOWeek(t *testing.T) {
+	for _, c := range []struct {
+		in  string
+		exp time.Time
+	}{
+		{"2006-W01-2", time.Date(2006, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"2009-W01-1", time.Date(2008, 12, 29, 0, 0, 0, 0, time.UTC)},
+		{"2009-W53-7", time.Date(2010, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"2024-W07-1", time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC)},
+	} {
+		res, err := Detect(c.in)
+		require.NoError(t, err, c.in)
+		require.Equal(t, "2006-W01-2", res.Layout)
+		require.Equal(t, c.exp, res.Time, c.in)
+		require.Equal(t, FieldDate|FieldWeekday, res.Fields, c.in)
+
+		y, w := res.Time.ISOWeek()
+		require.Equal(t, c.in[:8], fmt.Sprintf("%d-W%02d", y, w))
+	}
+
+	loc := time.FixedZone("X", 3600)
+	tm, err := ParseInLocation("2024-W07-1", loc)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 12, 0, 0, 0, 0, loc), tm)
+
+	for _, in := range []string{"2024-W00-1", "2024-W07-8", "2024-W07-0", "2023-W53-1", "2024-W7-1"} {
+		_, err := Parse(in)
+		require.Error(t, err, in)
+	}
+}
@@ -15,11 +15,7 @@
 // xtime is a time parser that parses the time without knowning the exact format.
 package xtime
 
-import (
-	"fmt"
-	"strings"
-	"time"
-)
+import "time"
 
 // TimeFormats is a list of commonly seen time formats from log messages
 var TimeFormats []string = []string{
@@ -57,132 +53,220 @@ var TimeFormats []string = []string{
 	"1/2/2006 3:04:05 PM",
 	"1/2/06 3:04:05.000 PM",
 	"1/2/2006 15:04",
+	"2006-01-02",
+	"20060102",
+	"2006-W01-2",
+	"2006-002",
+	"15:04:05",
+	"15:04",
+	"20060102T150405Z0700",
+	"2006年1月2日 15:04:05",
+	"2006年1月2日",
 }
 
+// Parse parses the time string using the first of the TimeFormats that matches it.
+// Times without a zone or offset are returned in UTC. If none of the formats
+// matches, the time string is parsed as a numeric epoch timestamp, such as
+// 1696000000, 1696000000123 or 1696000000.123456, with the unit inferred from the
+// magnitude of the number.
 func Parse(t string) (time.Time, error) {
-	tx := strings.ToLower(t)
-	cur := timeTreeRoot
+	return defaultParser.ParseWith(t)
+}
 
-	for i, r := range tx {
-		typ := tnType(r)
+// ParseInLocation is like Parse, but times without a zone or offset are returned in
+// the given location, such as time.Local.
+func ParseInLocation(t string, loc *time.Location) (time.Time, error) {
+	return defaultParser.ParseWith(t, WithLocation(loc))
+}
 
-		for _, n := range cur.children {
-			if (n.ntype == timeNodeDigitOrSpace && (typ == timeNodeDigit || typ == timeNodeSpace)) ||
-				(n.ntype == typ && (typ != timeNodeLiteral || (typ == timeNodeLiteral && rune(n.value) == r))) {
+// ParseWith is like Parse, but with options that change how the time string is
+// parsed.
+func ParseWith(t string, opts ...Option) (time.Time, error) {
+	return defaultParser.ParseWith(t, opts...)
+}
 
-				cur = n
-				break
-			}
-		}
+// Detect parses the time string like ParseWith, and also reports which of the
+// TimeFormats matched.
+func Detect(t string, opts ...Option) (Result, error) {
+	return defaultParser.Detect(t, opts...)
+}
 
-		if cur.final && i == len(tx)-1 {
-			return time.Parse(TimeFormats[cur.subtype], t)
-		}
-	}
+// ParseFormat parses the time string like Parse, and also returns which of the
+// TimeFormats matched, so it can be used for the rest of the input.
+func ParseFormat(t string) (time.Time, string, error) {
+	return defaultParser.ParseFormat(t)
+}
 
-	return time.Time{}, fmt.Errorf("Unknown time format")
+// DetectAll returns a result for every one of the TimeFormats that accepts the time
+// string.
+func DetectAll(t string, opts ...Option) []Result {
+	return defaultParser.DetectAll(t, opts...)
 }
 
-type timeNodeType int
+// Result is a parsed time, along with the format that matched.
+type Result struct {
+	Time   time.Time
+	Layout string    // The layout used, empty for epoch timestamps
+	Index  int       // Index of the format in TimeFormats, or in the Parser's formats, -1 if none
+	Epoch  EpochUnit // The unit used if the time string is an epoch timestamp, EpochAuto otherwise
+
+	// Ambiguous is true if the numeric date could also be read in another order,
+	// such as 03/02/2024. Layout is the format with its date fields in the order
+	// used, see WithDateOrder.
+	Ambiguous bool
 
+	// Fields are the components of the time that were in the time string. The
+	// others are zero, such as the time of a date alone.
+	Fields Fields
+
+	// UnknownZone is true if the time zone abbreviation, such as XYZ, is unknown,
+	// in which case the time has a zero offset, see WithStrictZones.
+	UnknownZone bool
+
+	// Precision is the number of digits of the fraction of a second in the time
+	// string, 0 if it has none.
+	Precision int
+
+	// Zone is how the time zone was given in the time string.
+	Zone ZoneKind
+
+	// YearInferred is true if the time string has no year, and the year was
+	// inferred from the reference time, see WithReference.
+	YearInferred bool
+}
+
+// timeNode is a node of the format tree. Each node matches one layout element, and
+// formats that start with the same elements share the same nodes.
 type timeNode struct {
-	ntype    timeNodeType
-	value    rune
+	elem     layoutElem
 	final    bool
-	subtype  int
+	subtype  int // Index of the format that ends at this node, if final
 	children []*timeNode
 }
 
-const (
-	timeNodeRoot timeNodeType = iota
-	timeNodeLeaf
-	timeNodeDigit
-	timeNodeLetter
-	timeNodeLiteral
-	timeNodeSpace
-	timeNodeDigitOrSpace
-	timeNodePlusOrMinus
-)
-
 var (
-	timeTreeRoot *timeNode
+	// defaultParser is the parser used by the package level functions. It is built
+	// from TimeFormats when the package is initialized, so changing TimeFormats
+	// afterwards has no effect; use a Parser instead.
+	defaultParser *Parser
 )
 
 func init() {
-	timeTreeRoot = buildTimeTree()
+	defaultParser = NewParser(TimeFormats...)
 }
 
-func buildTimeTree() *timeNode {
-	root := &timeNode{ntype: timeNodeRoot}
-
-	for i, f := range TimeFormats {
-		tf := strings.ToLower(f)
-		parent := root
+// insert adds the format elements to the tree rooted at root, marking the last node
+// as final for the format index.
+func (root *timeNode) insert(elems []layoutElem, index int) {
+	parent := root
 
-		for _, r := range tf {
-			typ := tnType(r)
-
-			hasChild := false
-			var child *timeNode
-
-			for _, child = range parent.children {
-				if (child.ntype == typ && (typ != timeNodeLiteral || (typ == timeNodeLiteral && child.value == r))) ||
-					(child.ntype == timeNodeDigitOrSpace && (typ == timeNodeDigit || typ == timeNodeSpace)) {
-					hasChild = true
-					break
-				} else if child.ntype == timeNodeDigit && typ == timeNodeDigitOrSpace {
-					child.ntype = timeNodeDigitOrSpace
-					hasChild = true
-					break
-				}
-			}
+	for _, e := range elems {
+		var child *timeNode
 
-			if hasChild == false {
-				child = &timeNode{ntype: typ, value: r}
-				parent.children = append(parent.children, child)
+		for _, c := range parent.children {
+			if c.elem == e {
+				child = c
+				break
 			}
+		}
 
-			parent = child
+		if child == nil {
+			child = &timeNode{elem: e}
+			parent.children = append(parent.children, child)
 		}
 
+		parent = child
+	}
+
+	// The same elements means the same format, keep the first one
+	if !parent.final {
 		parent.final = true
-		parent.subtype = i
+		parent.subtype = index
 	}
+}
 
-	return root
+// candidate is a format that matched the time string up to end. A loose candidate
+// only matched because the seconds took a fraction that isn't in the format.
+type candidate struct {
+	index, end int
+	loose      bool
 }
 
-func tnType(r rune) timeNodeType {
-	switch {
-	case r >= '0' && r <= '9':
-		return timeNodeDigit
-	case r >= 'a' && r <= 'y':
-		return timeNodeLetter
-	case r == ' ':
-		return timeNodeSpace
-	case r == '_':
-		return timeNodeDigitOrSpace
-	case r == '+' || r == '-':
-		return timeNodePlusOrMinus
-	case r >= 'A' && r <= 'Y':
-		return timeNodeLetter
-	case r == 'z' || r == 'Z':
-		return timeNodePlusOrMinus
+// matcher matches a time string against the format tree. Elements such as
+// fractional seconds or names can match different lengths of the time string, so
+// the matcher backtracks and tries every one of them, along every branch of the
+// tree. This finds every format that could accept the time string, even when
+// formats share a prefix and only diverge later.
+type matcher struct {
+	t      string
+	o      *options
+	prefix bool // Also report the formats that only match the start of t
+}
+
+// match returns the candidates for the time string, best first, appended to cands.
+// The candidates are passed along instead of kept in the matcher so they can stay
+// on the stack of the caller, and matching doesn't allocate.
+func (m *matcher) match(root *timeNode, cands []candidate) []candidate {
+	cands = m.walk(root, 0, false, cands)
+
+	// There are only a few candidates, and sort.Slice allocates
+	for i := 1; i < len(cands); i++ {
+		for j := i; j > 0 && m.better(cands[j], cands[j-1]); j-- {
+			cands[j], cands[j-1] = cands[j-1], cands[j]
+		}
 	}
 
-	return timeNodeLiteral
+	return cands
 }
 
-func timeStep(r rune, cur *timeNode) *timeNode {
-	typ := tnType(r)
+// walk tries all the children of n at position pos of the time string, and adds
+// the final nodes reached to the candidates.
+func (m *matcher) walk(n *timeNode, pos int, loose bool, cands []candidate) []candidate {
+	var lens [maxElemLens]int
+
+	for _, c := range n.children {
+		k := c.elem.match(m.t[pos:], m.o, &lens)
+
+		for i, l := range lens[:k] {
+			var (
+				end = pos + l
+				lz  = loose || (k > 1 && i == 0 && (c.elem.kind == elemSecond || c.elem.kind == elemZeroSecond))
+			)
 
-	for _, n := range cur.children {
-		if (n.ntype == timeNodeDigitOrSpace && (typ == timeNodeDigit || typ == timeNodeSpace)) ||
-			(n.ntype == typ && (typ != timeNodeLiteral || (typ == timeNodeLiteral && rune(n.value) == r))) {
+			if c.final && (m.prefix || end == len(m.t)) {
+				cands = addCandidate(cands, candidate{c.subtype, end, lz})
+			}
+
+			if len(c.children) > 0 {
+				cands = m.walk(c, end, lz, cands)
+			}
+		}
+	}
+
+	return cands
+}
 
-			return n
+func addCandidate(cands []candidate, c candidate) []candidate {
+	for i, x := range cands {
+		if x.index == c.index && x.end == c.end {
+			// Keep the strict match if there is one
+			cands[i].loose = x.loose && c.loose
+			return cands
 		}
 	}
 
-	return nil
+	return append(cands, c)
+}
+
+// better returns true if a is a better candidate than b: the longest match if
+// prefix, then strict matches before loose ones, then the first format.
+func (m *matcher) better(a, b candidate) bool {
+	switch {
+	case m.prefix && a.end != b.end:
+		return a.end > b.end
+	case a.loose != b.loose:
+		return !a.loose
+	}
+
+	return a.index < b.index
 }
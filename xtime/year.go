@@ -0,0 +1,67 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import "time"
+
+const (
+	// yearFutureSlack is how far in the future of the reference time an inferred
+	// year can put a time, to allow for clock skew between the log source and the
+	// reader.
+	yearFutureSlack = 7 * 24 * time.Hour
+
+	// yearSearchRange is how many years before the reference time are searched,
+	// which is enough to find the previous Feb 29.
+	yearSearchRange = 8
+)
+
+// inferYear returns t, which was parsed without a year, in the year that puts it
+// closest to ref without being more than yearFutureSlack in the future of ref.
+// So "Dec 31 23:59:59" read on Jan 1 is in the previous year, and Feb 29 is always
+// in a leap year.
+func inferYear(t, ref time.Time) time.Time {
+	var (
+		best  time.Time
+		found bool
+		y     = ref.Year()
+	)
+
+	for cy := y + 1; cy >= y-yearSearchRange; cy-- {
+		c := time.Date(cy, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+		// Feb 29 is normalized to Mar 1 in years that are not leap years
+		if c.Day() != t.Day() || c.Sub(ref) > yearFutureSlack {
+			continue
+		}
+
+		if !found || absDuration(c.Sub(ref)) < absDuration(best.Sub(ref)) {
+			best, found = c, true
+		}
+	}
+
+	if !found {
+		return t
+	}
+
+	return best
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferYear(t *testing.T) {
+	tests := []struct {
+		ref, in, expected string
+	}{
+		{"2015-06-15T12:00:00Z", "Jun 10 08:00:00", "2015-06-10T08:00:00Z"},
+		{"2015-06-15T12:00:00Z", "Jun 20 08:00:00", "2015-06-20T08:00:00Z"},
+		{"2015-06-15T12:00:00Z", "Jul 20 08:00:00", "2014-07-20T08:00:00Z"},
+		{"2015-06-15T12:00:00Z", "Dec 20 08:00:00", "2014-12-20T08:00:00Z"},
+
+		// December -> January rollover, in both directions
+		{"2015-01-01T00:00:10Z", "Dec 31 23:59:59", "2014-12-31T23:59:59Z"},
+		{"2014-12-31T23:59:00Z", "Jan  1 00:01:00", "2015-01-01T00:01:00Z"},
+
+		// Feb 29 goes to the last leap year
+		{"2015-03-01T00:00:00Z", "Feb 29 10:00:00", "2012-02-29T10:00:00Z"},
+		{"2016-03-01T00:00:00Z", "Feb 29 10:00:00", "2016-02-29T10:00:00Z"},
+		{"2016-02-25T00:00:00Z", "Feb 29 10:00:00", "2016-02-29T10:00:00Z"},
+		{"2016-02-01T00:00:00Z", "Feb 29 10:00:00", "2012-02-29T10:00:00Z"},
+
+		// Years in the string are kept
+		{"2015-06-15T12:00:00Z", "Mon Jul 20 08:00:00 2020", "2020-07-20T08:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		ref, err := time.Parse(time.RFC3339, tt.ref)
+		require.NoError(t, err)
+
+		expected, err := time.Parse(time.RFC3339, tt.expected)
+		require.NoError(t, err)
+
+		actual, err := ParseWith(tt.in, WithReference(ref))
+		require.NoError(t, err, tt.in)
+		require.Equal(t, expected.UnixNano(), actual.UnixNano(), "%s: %s", tt.in, actual)
+	}
+
+	// Without a reference time, the year is 0
+	actual, err := Parse("Jun 10 08:00:00")
+	require.NoError(t, err)
+	require.Equal(t, 0, actual.Year())
+
+	// The reference time is in the default location
+	loc := time.FixedZone("", 2*3600)
+	actual, err = ParseWith("Jan  1 01:00:00", WithLocation(loc), WithReference(time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2014, 12, 31, 23, 0, 0, 0, time.UTC).UnixNano(), actual.UnixNano())
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	secondsPerHour = 3600
+)
+
+var (
+	// zoneAbbrevs are the offsets, in seconds east of UTC, of the common time zone
+	// abbreviations. The ambiguous ones resolve to the most common use: IST to India,
+	// CST to US Central, BST to British Summer Time and AST to Atlantic. Use
+	// WithZoneAbbrev to resolve them differently.
+	zoneAbbrevs = map[string]int{
+		// North America
+		"EST": -5 * secondsPerHour, "EDT": -4 * secondsPerHour,
+		"CST": -6 * secondsPerHour, "CDT": -5 * secondsPerHour,
+		"MST": -7 * secondsPerHour, "MDT": -6 * secondsPerHour,
+		"PST": -8 * secondsPerHour, "PDT": -7 * secondsPerHour,
+		"AKST": -9 * secondsPerHour, "AKDT": -8 * secondsPerHour,
+		"HST": -10 * secondsPerHour,
+		"AST": -4 * secondsPerHour, "ADT": -3 * secondsPerHour,
+		"NST": -3*secondsPerHour - 1800, "NDT": -2*secondsPerHour - 1800,
+
+		// South America
+		"ART": -3 * secondsPerHour, "BRT": -3 * secondsPerHour,
+
+		// Europe
+		"WET": 0, "WEST": 1 * secondsPerHour,
+		"CET": 1 * secondsPerHour, "CEST": 2 * secondsPerHour,
+		"EET": 2 * secondsPerHour, "EEST": 3 * secondsPerHour,
+		"BST": 1 * secondsPerHour,
+		"MSK": 3 * secondsPerHour,
+
+		// Africa
+		"WAT": 1 * secondsPerHour, "CAT": 2 * secondsPerHour, "SAST": 2 * secondsPerHour, "EAT": 3 * secondsPerHour,
+
+		// Asia
+		"PKT": 5 * secondsPerHour,
+		"IST": 5*secondsPerHour + 1800,
+		"ICT": 7 * secondsPerHour, "WIB": 7 * secondsPerHour,
+		"HKT": 8 * secondsPerHour, "SGT": 8 * secondsPerHour, "PHT": 8 * secondsPerHour, "WITA": 8 * secondsPerHour,
+		"JST": 9 * secondsPerHour, "KST": 9 * secondsPerHour, "WIT": 9 * secondsPerHour,
+
+		// Oceania
+		"AWST": 8 * secondsPerHour,
+		"ACST": 9*secondsPerHour + 1800, "ACDT": 10*secondsPerHour + 1800,
+		"AEST": 10 * secondsPerHour, "AEDT": 11 * secondsPerHour,
+		"NZST": 12 * secondsPerHour, "NZDT": 13 * secondsPerHour,
+	}
+)
+
+// resolveZone gives the time, parsed with a zone abbreviation such as PDT, the real
+// offset of the abbreviation. The time package only knows the abbreviations of the
+// location the time is parsed in, and makes up a zone with a zero offset for the
+// others. It returns false if the abbreviation is unknown.
+func resolveZone(t time.Time, o *options) (time.Time, bool) {
+	name, _ := t.Zone()
+
+	offset, ok := o.zones[name]
+	if !ok {
+		if t.Location() == o.loc || name == "UTC" || hasPrefix(name, "GMT") || name[0] == '+' || name[0] == '-' {
+			// Known to the location, or an explicit offset
+			return t, true
+		}
+
+		if offset, ok = zoneAbbrevs[name]; !ok {
+			return t, false
+		}
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(),
+		time.FixedZone(name, offset)), true
+}
+
+// zoneError is the error for an unknown zone abbreviation with WithStrictZones.
+func zoneError(t time.Time) error {
+	name, _ := t.Zone()
+	return fmt.Errorf("xtime/Parse: Unknown time zone abbreviation %q", name)
+}
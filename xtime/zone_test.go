@@ -0,0 +1,76 @@
+// Copyright (c) 2014 Dataence, LLC. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZoneAbbrev(t *testing.T) {
+	exp := time.Date(2024, 2, 12, 10, 0, 0, 0, time.UTC)
+
+	for _, c := range []struct {
+		in     string
+		offset time.Duration
+	}{
+		{"Mon, 12 Feb 2024 03:00:00 PDT", -7 * time.Hour},
+		{"Mon, 12 Feb 2024 02:00:00 PST", -8 * time.Hour},
+		{"Mon, 12 Feb 2024 12:00:00 CEST", 2 * time.Hour},
+		{"Mon, 12 Feb 2024 15:30:00 IST", 5*time.Hour + 30*time.Minute},
+		{"Mon, 12 Feb 2024 04:00:00 CST", -6 * time.Hour},
+		{"Mon, 12 Feb 2024 10:00:00 UTC", 0},
+		{"Mon, 12 Feb 2024 10:00:00 GMT", 0},
+	} {
+		res, err := Detect(c.in)
+		require.NoError(t, err, c.in)
+		require.False(t, res.UnknownZone, c.in)
+		require.Equal(t, exp.UnixNano(), res.Time.UnixNano(), c.in)
+
+		_, offset := res.Time.Zone()
+		require.Equal(t, int(c.offset/time.Second), offset, c.in)
+	}
+
+	// Ambiguous abbreviations resolved differently
+	tm, err := ParseWith("Mon, 12 Feb 2024 18:00:00 CST", WithZoneAbbrev("CST", 8*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, exp.UnixNano(), tm.UnixNano())
+
+	tm, err = ParseWith("Mon, 12 Feb 2024 10:00:00 IST", WithZoneAbbrev("IST", 0), WithZoneAbbrev("XYZ", time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, exp.UnixNano(), tm.UnixNano())
+
+	// Unknown abbreviations
+	res, err := Detect("Mon, 12 Feb 2024 10:00:00 XYZ")
+	require.NoError(t, err)
+	require.True(t, res.UnknownZone)
+	require.Equal(t, exp.UnixNano(), res.Time.UnixNano())
+
+	_, err = ParseWith("Mon, 12 Feb 2024 10:00:00 XYZ", WithStrictZones())
+	require.Error(t, err)
+
+	tm, err = ParseWith("Mon, 12 Feb 2024 11:00:00 XYZ", WithStrictZones(), WithZoneAbbrev("XYZ", time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, exp.UnixNano(), tm.UnixNano())
+
+	// An abbreviation the location knows keeps the location
+	loc := time.FixedZone("ABC", 3*3600)
+	tm, err = ParseWith("Mon, 12 Feb 2024 13:00:00 ABC", WithLocation(loc), WithStrictZones())
+	require.NoError(t, err)
+	require.Equal(t, exp.UnixNano(), tm.UnixNano())
+	require.Equal(t, loc, tm.Location())
+}